@@ -11,6 +11,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/oconnorjohnson/add-n-commit/internal/app"
 	"github.com/oconnorjohnson/add-n-commit/internal/config"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
 )
 
 func main() {
@@ -36,7 +37,28 @@ func main() {
 		cfg = config.Default()
 	}
 
-	log.Printf("Config loaded: API Key present: %v", cfg.OpenAIKey != "")
+	// Layer the repository's project config over the user config
+	if root, err := git.GetRepoRoot(); err == nil {
+		if cfg, err = cfg.WithProject(root); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if cfg, err = cfg.WithProfile(cfg.ActiveProfile); err != nil {
+		log.Fatal(err)
+	}
+
+	if cfg.ProjectFile() != "" {
+		log.Printf("Project config loaded from %s", cfg.ProjectFile())
+	}
+
+	apiKey, source, err := cfg.APIKey()
+	if err != nil {
+		log.Printf("Warning: Could not resolve API key: %v", err)
+	}
+	cfg.OpenAIKey = apiKey
+
+	log.Printf("Config loaded: API Key present: %v (%s)", cfg.OpenAIKey != "", source)
 
 	// Create and run the app
 	p := tea.NewProgram(
@@ -4,16 +4,21 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/oconnorjohnson/add-n-commit/internal/config"
+	"github.com/oconnorjohnson/add-n-commit/internal/draft"
 	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/message"
 	"github.com/oconnorjohnson/add-n-commit/internal/openai"
+	"github.com/oconnorjohnson/add-n-commit/internal/scan"
 	"github.com/oconnorjohnson/add-n-commit/internal/ui"
 )
 
@@ -30,6 +35,10 @@ const (
 	stateError
 	stateConfig
 	stateStagedFilesPrompt
+	stateSecretsWarning
+	stateTrailers
+	stateSplit
+	stateDrafts
 )
 
 type commitMode int
@@ -38,6 +47,8 @@ const (
 	modeAllInOne commitMode = iota
 	modeByFile
 	modeCustomPrompt
+	modeConventional
+	modeSplit
 )
 
 type Model struct {
@@ -57,18 +68,126 @@ type Model struct {
 	customPrompt    string
 	errorMsg        string
 	successMsg      string
+	violations      []message.Violation
+	findings        []scan.Finding
+	redactSecrets   bool // User chose to redact findings for this session
+	
+	// Trailer editor
+	trailers      []string // "Key: value" trailers added on commit
+	trailerView   trailerView
+	trailerCursor int
+	trailerInput  textinput.Model
+	trailerErr    string
+	authors       []string
+	authorCursor  int
+	
+	// Split mode
+	hunks        []git.Hunk
+	groups       []splitGroup
+	splitCursor  int
+	splitEditing bool
+	splitErr     string
 	
 	width  int
 	height int
+	layout ui.Layout
+	
+	// Changed files and their selection
+	fileTree *ui.FileTree
+	
+	// Side pane previews on wide terminals
+	preview     string // Diff of the file under the cursor
+	previewPath string
+	reviewDiff  string // Diff the message describes
+	
+	// Key bindings and the ? overlay listing them
+	keys     ui.KeyMap
+	help     help.Model
+	showHelp bool
 	
 	openaiClient *openai.Client
 	
 	// New field to track already staged files
 	alreadyStagedFiles []string
+	
+	// Index and HEAD at startup, restored when quitting without committing
+	indexTree string
+	indexHead string
+	
+	// Versions of the message generated and edited so far
+	history    []string
+	historyPos int
+	showDiff   bool
+	editorErr  string // Why the external editor couldn't be used
+	
+	// Git's commit message conventions
+	commentChar string
+	template    string // Contents of commit.template
+	
+	// Drafts
+	resumable  *draft.Draft  // Draft saved for the already staged files
+	drafts     []draft.Draft // Recent drafts offered in the picker
+	draftList  list.Model
+	draftTree  string // Staged tree the current draft is saved for
+	draftSaved bool
+	
+	// Commit options
+	signing    git.SigningConfig
+	noVerify   bool   // Skip hooks for this run
+	signMode   string // Sign setting for this run, overrides the config
+	signingKey string // Signing key for this run, overrides the config
+	
+	// Commit log
+	commitLog    []string
+	commitView   viewport.Model
+	commitCh     <-chan tea.Msg
+	committing   bool
+	commitErr    string
+	hookModified []string          // Files the failed commit's hooks changed
+	hookBefore   map[string]string // Their blobs before the hooks ran
+	splitDone    int      // Split commits made before a failure
+	quitPending  bool     // Ctrl+C was pressed while git commit was running
+	
+	// Success screen
+	summary    commitSummary
+	pushing    bool
+	pushCh     <-chan tea.Msg
+	pushOutput []string
+	pushStatus string
+	pushErr    string
+	
+	// Session mode
+	session    bool
+	sessionLog []git.CommitInfo
+	
+	// Amend and reword modes
+	amend    bool
+	reword   string      // Revision whose message is rewritten
+	changes  git.Changes // Changes the message describes
+	lastMsg  string      // Message of the commit being amended or reworded
+	pushedTo string      // Upstream that already contains the commit, if any
+}
+
+// Options changes what the app commits
+type Options struct {
+	Amend    bool   // Rewrite HEAD with the staged changes instead of committing
+	Reword   string // Only rewrite the message of this commit
+	Session  bool   // Return to file selection after each commit
+	NoVerify bool   // Skip the pre-commit and commit-msg hooks
+
+	// Signing for this run, overriding the sign and signing_key settings
+	Sign       bool
+	NoSign     bool
+	SigningKey string
 }
 
 // New creates a new app model
 func New(cfg *config.Config) *Model {
+	return NewWithOptions(cfg, Options{})
+}
+
+// NewWithOptions creates a new app model with the given options
+func NewWithOptions(cfg *config.Config, opts Options) *Model {
 	m := &Model{
 		state:    stateFileSelection,
 		config:   cfg,
@@ -76,7 +195,25 @@ func New(cfg *config.Config) *Model {
 		textarea: textarea.New(),
 		width:    80,  // Default width
 		height:   24,  // Default height
+		amend:    opts.Amend,
+		reword:   opts.Reword,
+		session:  opts.Session,
+		noVerify: opts.NoVerify,
+		signing:  git.GetSigningConfig(),
+	}
+	if opts.Sign {
+		m.signMode = config.SignAlways
+	} else if opts.NoSign {
+		m.signMode = config.SignNever
 	}
+	m.signingKey = opts.SigningKey
+	m.snapshotIndex()
+	// Unknown actions are reported by main before the app starts
+	m.keys, _ = ui.NewKeyMap(cfg.Keys)
+	m.help = help.New()
+	m.commentChar = git.GetCommentChar()
+	// A template that can't be read only loses the guidance
+	m.template, _ = git.GetCommitTemplate()
 	
 	// Initialize text input for custom prompt
 	ti := textinput.New()
@@ -91,6 +228,17 @@ func New(cfg *config.Config) *Model {
 	apiKeyInput.EchoMode = textinput.EchoPassword
 	m.apiKeyInput = apiKeyInput
 	
+	// Initialize trailer input
+	trailerInput := textinput.New()
+	trailerInput.Placeholder = "Key: value"
+	trailerInput.CharLimit = 200
+	m.trailerInput = trailerInput
+	
+	// Initialize commit log
+	m.commitView = viewport.New(76, 10)
+	m.commitView.KeyMap.Up = m.keys.Up
+	m.commitView.KeyMap.Down = m.keys.Down
+	
 	// Set up spinner
 	m.spinner.Spinner = spinner.Dot
 	
@@ -99,15 +247,28 @@ func New(cfg *config.Config) *Model {
 	m.fileList = list.New([]list.Item{}, delegate, 76, 14)
 	m.fileList.SetShowStatusBar(false)
 	m.fileList.SetFilteringEnabled(false)
+	m.bindListKeys(&m.fileList)
 	
 	modeDelegate := ui.NewModeDelegate()
 	m.modeList = list.New([]list.Item{}, modeDelegate, 76, 10)
 	m.modeList.SetShowStatusBar(false)
 	m.modeList.SetFilteringEnabled(false)
+	m.bindListKeys(&m.modeList)
+	
+	m.draftList = list.New([]list.Item{}, ui.NewDraftDelegate(), 76, 10)
+	m.draftList.SetShowStatusBar(false)
+	m.draftList.SetShowTitle(false)
+	m.draftList.SetFilteringEnabled(false)
+	m.bindListKeys(&m.draftList)
+	m.resize()
 	
 	// Initialize OpenAI client if API key is available
-	if cfg.OpenAIKey != "" {
-		m.openaiClient = openai.NewClient(cfg.OpenAIKey, cfg.Model, cfg.Temperature)
+	m.openaiClient = newClient(cfg)
+	
+	// Rewording never stages anything
+	if m.reword != "" {
+		m.setupModeList()
+		m.state = stateModeSelection
 	}
 	
 	return m
@@ -115,12 +276,25 @@ func New(cfg *config.Config) *Model {
 
 func (m *Model) Init() tea.Cmd {
 	// Check if we need to configure API key first
-	if m.config.OpenAIKey == "" {
+	if m.config.OpenAIKey == "" && m.config.NeedsAPIKey() {
 		m.state = stateConfig
 		m.apiKeyInput.Focus()
 		return textinput.Blink
 	}
 	
+	if m.reword != "" {
+		return m.loadTarget
+	}
+	
+	// Staged changes are folded into the amended commit, so there is
+	// nothing to ask about them
+	if m.amend {
+		return tea.Batch(
+			m.loadFiles,
+			m.loadTarget,
+		)
+	}
+	
 	// Start with loading files
 	return tea.Batch(
 		m.loadFiles,
@@ -133,16 +307,46 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		m.resize()
+		return m, m.loadPreview()
+		
+	case previewLoadedMsg:
+		if msg.path == m.previewPath {
+			m.preview = msg.diff
+		}
+		return m, nil
+		
+	case reviewDiffLoadedMsg:
+		m.reviewDiff = msg.diff
 		return m, nil
 		
 	case tea.KeyMsg:
 		// Handle Ctrl+C globally
 		if key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))) {
+			// Restoring the index under a running git commit could commit
+			// the wrong tree, so wait for it to finish
+			if m.committing {
+				m.quitPending = true
+				return m, nil
+			}
+			
 			// Cleanup before quitting
 			m.cleanup()
 			return m, tea.Quit
 		}
 		
+		// The help overlay takes all keys until it is closed
+		if m.showHelp {
+			if key.Matches(msg, m.keys.Help, m.keys.Back, m.keys.Quit) {
+				m.showHelp = false
+			}
+			return m, nil
+		}
+		if !m.typing() && key.Matches(msg, m.keys.Help) {
+			m.showHelp = true
+			return m, nil
+		}
+		
 		switch m.state {
 		case stateConfig:
 			return m.updateConfig(msg)
@@ -156,20 +360,48 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateEditing(msg)
 		case stateStagedFilesPrompt:
 			return m.updateStagedFilesPrompt(msg)
-		case stateSuccess, stateError:
+		case stateSecretsWarning:
+			return m.updateSecretsWarning(msg)
+		case stateTrailers:
+			return m.updateTrailers(msg)
+		case stateSplit:
+			return m.updateSplit(msg)
+		case stateDrafts:
+			return m.updateDrafts(msg)
+		case stateCommitting:
+			return m.updateCommitting(msg)
+		case stateSuccess:
+			return m.updateSuccess(msg)
+		case stateError:
+			m.cleanup()
 			return m, tea.Quit
 		}
 		
 	case filesLoadedMsg:
+		// Rebuild the tree even without files, so a session doesn't keep
+		// the previous commit's rows and selections
 		m.files = msg.files
+		m.setupFileList()
+		m.previewPath = ""
 		if len(m.files) > 0 {
-			m.setupFileList()
+			return m, m.loadPreview()
+		} else if m.amend {
+			// Nothing to add, only reword the last commit
+			m.setupModeList()
+			m.state = stateModeSelection
 		}
 		return m, nil
 		
+	case targetLoadedMsg:
+		m.lastMsg = msg.message
+		m.changes = msg.changes
+		m.pushedTo = msg.pushedTo
+		return m, nil
+		
 	case stagedFilesFoundMsg:
 		if len(msg.files) > 0 {
 			m.alreadyStagedFiles = msg.files
+			m.resumable = msg.draft
 			m.state = stateStagedFilesPrompt
 		}
 		return m, nil
@@ -187,17 +419,103 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.generateCommitMessage(),
 		)
 		
+	case secretsFoundMsg:
+		m.findings = msg.findings
+		m.state = stateSecretsWarning
+		return m, nil
+		
 	case commitMessageGeneratedMsg:
 		m.generatedMsg = msg.message
+		if m.config.Lint.AutoFix {
+			m.generatedMsg = message.Fix(m.generatedMsg, m.lintRules())
+		}
 		m.state = stateReviewing
 		m.textarea.SetValue(m.generatedMsg)
+		m.violations = m.validateMessage(m.generatedMsg)
+		m.recordVersion(m.generatedMsg)
+		// The index may have changed since the last draft was saved
+		m.draftTree = ""
+		m.saveDraft()
+		m.reviewDiff = ""
+		return m, m.loadPreview()
+		
+	case splitProposedMsg:
+		m.hunks = msg.hunks
+		m.groups = msg.groups
+		m.splitCursor = 0
+		m.splitDone = 0
+		m.splitErr = ""
+		m.state = stateSplit
+		return m, nil
+		
+	case splitCommittedMsg:
+		m.committing = false
+		if m.quitPending {
+			return m.quitAfterCommit(true)
+		}
+		if m.session {
+			return m.nextCommit(msg.count)
+		}
+		m.successMsg = fmt.Sprintf("✓ Created %d commits successfully!", msg.count)
+		m.summary = msg.summary
+		m.state = stateSuccess
 		return m, nil
 		
 	case commitSuccessMsg:
+		m.committing = false
+		m.markDraftCommitted()
+		if m.quitPending {
+			return m.quitAfterCommit(true)
+		}
+		if m.session {
+			return m.nextCommit(1)
+		}
 		m.successMsg = "✓ Changes committed successfully!"
+		if m.reword != "" {
+			m.successMsg = fmt.Sprintf("✓ Reworded %s successfully!", m.reword)
+		}
+		m.summary = msg.summary
 		m.state = stateSuccess
 		return m, nil
 		
+	case editorClosedMsg:
+		return m.editorClosed(msg)
+		
+	case commitOutputMsg:
+		m.addCommitOutput(msg)
+		return m, waitForMsg(m.commitCh)
+		
+	case commitFailedMsg:
+		m.committing = false
+		m.commitErr = msg.err.Error()
+		m.hookModified = msg.modified
+		m.hookBefore = msg.before
+		m.saveDraft()
+		if m.selectedMode == modeSplit {
+			// Keep the groups that weren't committed for a retry
+			m.splitDone += msg.committed
+			m.groups = m.groups[msg.committed:]
+			m.splitCursor = 0
+		}
+		if m.quitPending {
+			return m.quitAfterCommit(false)
+		}
+		return m, nil
+		
+	case pushOutputMsg:
+		m.addPushOutput(msg)
+		return m, waitForMsg(m.pushCh)
+		
+	case pushDoneMsg:
+		m.pushing = false
+		if msg.err != nil {
+			m.pushErr = msg.err.Error()
+		} else {
+			m.summary.upstream = git.GetUpstream()
+			m.pushStatus = "Pushed to " + m.summary.upstream
+		}
+		return m, nil
+		
 	case errorMsg:
 		m.errorMsg = msg.err.Error()
 		m.state = stateError
@@ -210,11 +528,14 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateFileSelection:
 		if m.fileList.Items() != nil {
 			m.fileList, cmd = m.fileList.Update(msg)
+			cmd = tea.Batch(cmd, m.loadPreview())
 		}
 	case stateModeSelection:
 		if m.modeList.Items() != nil {
 			m.modeList, cmd = m.modeList.Update(msg)
 		}
+	case stateDrafts:
+		m.draftList, cmd = m.draftList.Update(msg)
 	case stateGenerating:
 		m.spinner, cmd = m.spinner.Update(msg)
 	case stateEditing:
@@ -225,6 +546,8 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case stateConfig:
 		m.apiKeyInput, cmd = m.apiKeyInput.Update(msg)
+	case stateCommitting:
+		m.commitView, cmd = m.commitView.Update(msg)
 	}
 	
 	return m, cmd
@@ -252,11 +575,24 @@ func (m *Model) View() string {
 		content = m.viewEditing()
 	case stateStagedFilesPrompt:
 		content = m.viewStagedFilesPrompt()
+	case stateSecretsWarning:
+		content = m.viewSecretsWarning()
+	case stateTrailers:
+		content = m.viewTrailers()
+	case stateSplit:
+		content = m.viewSplit()
+	case stateDrafts:
+		content = m.viewDrafts()
+	case stateCommitting:
+		content = m.viewCommitting()
 	case stateSuccess:
 		content = m.viewSuccess()
 	case stateError:
 		content = m.viewError()
 	}
+	if m.showHelp {
+		content = m.viewHelpOverlay()
+	}
 	
 	return ui.CenterInWindow(content, m.width, m.height)
 }
@@ -267,42 +603,62 @@ func (m *Model) viewConfig() string {
 		"%s\n\n%s\n\n%s",
 		ui.Title("Configure OpenAI API Key"),
 		m.apiKeyInput.View(),
-		ui.Subtle("Press Enter to save, Esc to quit"),
+		m.viewHelp(),
 	)
 }
 
 func (m *Model) viewFileSelection() string {
 	if len(m.files) == 0 {
+		if len(m.sessionLog) > 0 {
+			return ui.Title("All changes committed") + m.viewSessionLog() + "\n\n" + m.viewHelp()
+		}
 		return ui.Title("No changes detected") + "\n\n" + ui.Subtle("Make some changes and run again!")
 	}
 	
 	// Debug: show file count
 	title := fmt.Sprintf("Select files to stage (%d files)", len(m.files))
+	if m.amend {
+		title = fmt.Sprintf("Select files to add to the last commit (%d files)", len(m.files))
+	}
+	
+	// Preview the file under the cursor next to the list on wide terminals
+	height := m.fileList.Height()
+	files := m.layout.Join(m.fileList.View(), m.viewDiffPane(m.previewPath, m.preview, height-2), height)
 	
 	return fmt.Sprintf(
-		"%s\n\n%s\n\n%s",
+		"%s\n\n%s%s\n\n%s",
 		ui.Title(title),
-		m.fileList.View(),
-		ui.Subtle("Space: toggle, a: all/none, Enter: continue, q: quit"),
+		files,
+		m.viewSessionLog(),
+		m.viewHelp(),
 	)
 }
 
 func (m *Model) viewModeSelection() string {
 	return fmt.Sprintf(
-		"%s\n\n%s\n\n%s",
+		"%s\n%s\n\n%s\n\n%s",
 		ui.Title("Select commit message mode"),
+		ui.Subtle(m.profileSummary()),
 		m.modeList.View(),
-		ui.Subtle("Enter: select, q: quit"),
+		m.viewHelp(),
 	)
 }
 
+func (m *Model) profileSummary() string {
+	profile := m.config.ActiveProfile
+	if profile == "" {
+		profile = "default"
+	}
+	return fmt.Sprintf("Profile: %s (%s, %s)", profile, m.config.Provider, m.config.Model)
+}
+
 func (m *Model) viewGenerating() string {
 	// Create a preview area with spinner
 	previewBox := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("62")).
 		Padding(1, 2).
-		Width(60).
+		Width(min(60, m.layout.Main.Width-2)).
 		Height(10).
 		Render(fmt.Sprintf("%s Generating commit message...", m.spinner.View()))
 	
@@ -315,12 +671,93 @@ func (m *Model) viewGenerating() string {
 }
 
 func (m *Model) viewReviewing() string {
-	return fmt.Sprintf(
-		"%s\n\n%s\n\n%s",
-		ui.Title("Review commit message"),
+	title := "Review commit message"
+	if m.amend {
+		title = "Review amended commit message"
+	} else if m.reword != "" {
+		title = fmt.Sprintf("Review new message for %s", m.reword)
+	}
+	
+	body := fmt.Sprintf(
+		"%s%s%s%s%s%s\n\n%s",
 		m.textarea.View(),
-		ui.Subtle("Enter: commit, e: edit, r: regenerate, q: quit"),
+		m.viewHistory(),
+		m.viewEditorError(),
+		m.viewPendingTrailers(),
+		m.viewViolations(),
+		m.viewPushedWarning(),
+		m.viewCommitOptions(),
 	)
+	
+	// Show the diff being described next to the message on wide terminals
+	height := max(lipgloss.Height(body), m.layout.Main.Height-6)
+	
+	return fmt.Sprintf(
+		"%s\n\n%s\n%s",
+		ui.Title(title),
+		m.layout.Join(body, m.viewDiffPane("Changes", m.reviewDiff, height-2), height),
+		m.viewHelp(),
+	)
+}
+
+// viewCommitOptions shows how the commit will be signed and whether hooks
+// run
+func (m *Model) viewCommitOptions() string {
+	opts := m.commitOptions()
+	
+	signing := "Signing: off"
+	if opts.Sign {
+		key := opts.SigningKey
+		if key == "" {
+			key = m.signing.Key
+		}
+		if key == "" {
+			key = "default key"
+		}
+		signing = fmt.Sprintf("Signing: %s (%s)", m.signing.Format, key)
+	}
+	
+	hooks := "Hooks: run"
+	if m.reword != "" {
+		hooks = "Hooks: none (reword)"
+	} else if opts.NoVerify {
+		hooks = ui.WarningStyle.Render("Hooks: skipped (--no-verify)")
+	}
+	
+	return ui.Subtle(signing+" · ") + hooks
+}
+
+// viewPushedWarning warns that amending or rewording rewrites a published
+// commit
+func (m *Model) viewPushedWarning() string {
+	if m.pushedTo == "" {
+		return ""
+	}
+	return "\n\n" + ui.WarningStyle.Render(fmt.Sprintf(
+		"! The commit is already pushed to %s, rewriting it will require a force push", m.pushedTo))
+}
+
+func (m *Model) viewViolations() string {
+	if len(m.violations) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n")
+	for _, v := range m.violations {
+		line := fmt.Sprintf("%s (%s)", v.Message, v.Rule)
+		if v.Severity == message.SeverityError {
+			b.WriteString("\n" + ui.ErrorStyle.Render("✗ "+line))
+		} else {
+			b.WriteString("\n" + ui.WarningStyle.Render("! "+line))
+		}
+	}
+	if m.commitBlocked() {
+		b.WriteString("\n\n" + ui.Subtle(fmt.Sprintf("Fix the errors above before committing (%s: edit, %s: regenerate)",
+			m.keys.Edit.Help().Key, m.keys.Regenerate.Help().Key)))
+	}
+
+	return b.String()
 }
 
 func (m *Model) viewEditing() string {
@@ -329,15 +766,16 @@ func (m *Model) viewEditing() string {
 			"%s\n\n%s\n\n%s",
 			ui.Title("Enter additional context"),
 			m.textinput.View(),
-			ui.Subtle("Enter: generate message, Esc: back"),
+			m.viewHelp(),
 		)
 	}
 	
 	return fmt.Sprintf(
-		"%s\n\n%s\n\n%s",
+		"%s\n\n%s%s\n\n%s",
 		ui.Title("Edit commit message"),
 		m.textarea.View(),
-		ui.Subtle("Ctrl+Enter: commit, Esc: cancel"),
+		m.viewViolations(),
+		m.viewHelp(),
 	)
 }
 
@@ -347,21 +785,24 @@ func (m *Model) viewStagedFilesPrompt() string {
 	return fmt.Sprintf(
 		"%s\n\n%s\n\n%s\n\n%s",
 		ui.Title("Already Staged Files Detected"),
-		fmt.Sprintf("The following files are already staged:\n  - %s", fileList),
+		fmt.Sprintf("The following files are already staged:\n  - %s", fileList)+m.viewResumableDraft(),
 		"What would you like to do?",
-		ui.Subtle("c: continue with staged files, u: unstage and start fresh, q: quit"),
+		m.viewHelp(),
 	)
 }
 
-func (m *Model) viewSuccess() string {
-	style := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("42")).
-		Bold(true)
-		
+func (m *Model) viewSecretsWarning() string {
+	var b strings.Builder
+	for _, f := range m.findings {
+		b.WriteString(fmt.Sprintf("\n  %s  %s  %s", ui.ErrorStyle.Render(f.Rule), f.File, ui.Subtle(f.Match)))
+	}
+	
 	return fmt.Sprintf(
-		"%s\n\n%s",
-		style.Render(m.successMsg),
-		ui.Subtle("Press any key to exit"),
+		"%s\n\n%s\n%s\n\n%s",
+		ui.Title("Possible Secrets Detected"),
+		fmt.Sprintf("%d possible secret(s) would be sent to the model:", len(m.findings)),
+		b.String(),
+		m.viewHelp(),
 	)
 }
 
@@ -374,36 +815,29 @@ func (m *Model) viewError() string {
 		"%s\n\n%s\n\n%s",
 		ui.Title("Error"),
 		style.Render(m.errorMsg),
-		ui.Subtle("Press any key to exit"),
+		m.viewHelp(),
 	)
 }
 
 // Helper methods
 func (m *Model) setupFileList() {
-	items := make([]list.Item, len(m.files))
-	for i, f := range m.files {
-		items[i] = ui.FileItem{
-			File:     f,
-			Selected: false,
-		}
+	// Keep the tree or flat view chosen earlier in the session
+	flat := !m.config.FileTree
+	if m.fileTree != nil {
+		flat = m.fileTree.Flat()
 	}
+	m.fileTree = ui.NewFileTree(m.files, flat)
 	
 	delegate := ui.NewFileDelegate()
-	width := m.width - 4
-	if width < 40 {
-		width = 40
-	}
-	height := m.height - 10
-	if height < 10 {
-		height = 10
-	}
-	m.fileList = list.New(items, delegate, width, height)
+	m.fileList = list.New(m.fileTree.Items(), delegate, 0, 0)
 	m.fileList.Title = "Files"
 	m.fileList.SetShowStatusBar(false)
 	m.fileList.SetFilteringEnabled(false)
 	m.fileList.Styles.Title = ui.TitleStyle
 	m.fileList.Styles.PaginationStyle = ui.SubtleStyle
 	m.fileList.Styles.HelpStyle = ui.SubtleStyle
+	m.bindListKeys(&m.fileList)
+	m.resize()
 }
 
 func (m *Model) setupModeList() {
@@ -411,26 +845,30 @@ func (m *Model) setupModeList() {
 		ui.ModeItem{Name: "All-in-one summary", Mode: int(modeAllInOne)},
 		ui.ModeItem{Name: "File-by-file summary", Mode: int(modeByFile)},
 		ui.ModeItem{Name: "Custom prompt", Mode: int(modeCustomPrompt)},
+		ui.ModeItem{Name: "Conventional commit", Mode: int(modeConventional)},
 	}
 	
-	delegate := ui.NewModeDelegate()
-	width := m.width - 4
-	if width < 40 {
-		width = 40
+	// Amending and rewording describe a single commit
+	if !m.amend && m.reword == "" {
+		items = append(items, ui.ModeItem{Name: "Split into multiple commits", Mode: int(modeSplit)})
 	}
-	m.modeList = list.New(items, delegate, width, 10)
+	
+	delegate := ui.NewModeDelegate()
+	m.modeList = list.New(items, delegate, 0, 0)
 	m.modeList.Title = "Modes"
 	m.modeList.SetShowStatusBar(false)
 	m.modeList.SetFilteringEnabled(false)
 	m.modeList.Styles.Title = ui.TitleStyle
 	m.modeList.Styles.PaginationStyle = ui.SubtleStyle
 	m.modeList.Styles.HelpStyle = ui.SubtleStyle
+	m.bindListKeys(&m.modeList)
+	m.resize()
 }
 
 // Update helpers
 func (m *Model) updateConfig(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyEnter:
+	switch {
+	case key.Matches(msg, m.keys.Enter):
 		apiKey := m.apiKeyInput.Value()
 		if apiKey == "" {
 			m.errorMsg = "API key cannot be empty"
@@ -439,17 +877,25 @@ func (m *Model) updateConfig(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		
 		m.config.OpenAIKey = apiKey
-		if err := m.config.Save(); err != nil {
+		if err := m.config.User().SetAPIKey(apiKey); err != nil {
 			m.errorMsg = fmt.Sprintf("Failed to save config: %v", err)
 			m.state = stateError
 			return m, nil
 		}
 		
-		m.openaiClient = openai.NewClient(apiKey, m.config.Model, m.config.Temperature)
+		m.openaiClient = newClient(m.config)
+		if m.reword != "" {
+			m.setupModeList()
+			m.state = stateModeSelection
+			return m, m.loadTarget
+		}
 		m.state = stateFileSelection
+		if m.amend {
+			return m, tea.Batch(m.loadFiles, m.loadTarget)
+		}
 		return m, m.loadFiles
 		
-	case tea.KeyEsc:
+	case key.Matches(msg, m.keys.Back):
 		return m, tea.Quit
 	}
 	
@@ -461,49 +907,50 @@ func (m *Model) updateConfig(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m *Model) updateFileSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	
-	switch msg.String() {
-	case "q":
+	// Without files, or before they are loaded, there is only quitting
+	if m.fileTree == nil || len(m.files) == 0 {
+		if key.Matches(msg, m.keys.Quit) {
+			m.cleanup()
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+	
+	switch {
+	case key.Matches(msg, m.keys.Quit):
 		m.cleanup()
 		return m, tea.Quit
 		
-	case " ":
-		if i, ok := m.fileList.SelectedItem().(ui.FileItem); ok {
-			idx := m.fileList.Index()
-			items := m.fileList.Items()
-			i.Selected = !i.Selected
-			items[idx] = i
-			m.fileList.SetItems(items)
-		}
-		
-	case "a":
-		// Toggle all
-		items := m.fileList.Items()
-		allSelected := true
-		for _, item := range items {
-			if fi, ok := item.(ui.FileItem); ok && !fi.Selected {
-				allSelected = false
-				break
-			}
+	case key.Matches(msg, m.keys.Toggle):
+		// Toggling a directory selects all of its files
+		if item := m.fileList.SelectedItem(); item != nil {
+			m.fileTree.Toggle(item)
+			m.refreshFileList()
 		}
+		return m, nil
 		
-		for i, item := range items {
-			if fi, ok := item.(ui.FileItem); ok {
-				fi.Selected = !allSelected
-				items[i] = fi
-			}
-		}
-		m.fileList.SetItems(items)
+	case key.Matches(msg, m.keys.ToggleAll):
+		m.fileTree.ToggleAll()
+		m.refreshFileList()
+		return m, nil
 		
-	case "enter":
-		// Collect selected files
-		m.selectedFiles = []string{}
-		for _, item := range m.fileList.Items() {
-			if fi, ok := item.(ui.FileItem); ok && fi.Selected {
-				m.selectedFiles = append(m.selectedFiles, fi.File.Path)
-			}
-		}
+	case key.Matches(msg, m.keys.Left):
+		m.collapseOrParent()
+		return m, m.loadPreview()
+		
+	case key.Matches(msg, m.keys.Right):
+		m.expand()
+		return m, nil
+		
+	case key.Matches(msg, m.keys.TreeView):
+		m.fileTree.SetFlat(!m.fileTree.Flat())
+		m.refreshFileList()
+		return m, m.loadPreview()
+		
+	case key.Matches(msg, m.keys.Enter):
+		m.selectedFiles = m.fileTree.Selected()
 		
-		if len(m.selectedFiles) == 0 {
+		if len(m.selectedFiles) == 0 && !m.amend {
 			m.errorMsg = "No files selected"
 			m.state = stateError
 			return m, nil
@@ -523,18 +970,28 @@ func (m *Model) updateFileSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	
 	// Always update the list to handle navigation
 	m.fileList, cmd = m.fileList.Update(msg)
-	return m, cmd
+	return m, tea.Batch(cmd, m.loadPreview())
 }
 
 func (m *Model) updateModeSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	
-	switch msg.String() {
-	case "q":
+	switch {
+	case key.Matches(msg, m.keys.Quit):
 		m.cleanup()
 		return m, tea.Quit
 		
-	case "enter":
+	case key.Matches(msg, m.keys.Profile):
+		if len(m.config.Profiles) > 0 {
+			return m.switchProfile()
+		}
+		return m, nil
+		
+	case key.Matches(msg, m.keys.Drafts):
+		m.openDraftPicker()
+		return m, nil
+		
+	case key.Matches(msg, m.keys.Enter):
 		if i, ok := m.modeList.SelectedItem().(ui.ModeItem); ok {
 			return m, func() tea.Msg {
 				return commitModeSelectedMsg{mode: commitMode(i.Mode)}
@@ -548,20 +1005,51 @@ func (m *Model) updateModeSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) updateReviewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "q":
+	m.editorErr = ""
+	
+	switch {
+	case key.Matches(msg, m.keys.Quit):
 		m.cleanup()
 		return m, tea.Quit
 		
-	case "enter":
-		return m, m.commitChanges()
+	case key.Matches(msg, m.keys.Enter):
+		return m.commitIfValid()
 		
-	case "e":
+	case key.Matches(msg, m.keys.Edit):
 		m.state = stateEditing
 		m.textarea.Focus()
 		return m, textarea.Blink
 		
-	case "r":
+	case key.Matches(msg, m.keys.OpenEditor):
+		return m, m.openEditor(m.textarea.Value())
+		
+	case key.Matches(msg, m.keys.Fix):
+		m.fixMessage()
+		return m, nil
+		
+	case key.Matches(msg, m.keys.Trailers):
+		return m.openTrailerEditor()
+		
+	case key.Matches(msg, m.keys.ToggleHooks):
+		// Rewording runs no hooks
+		if m.reword == "" {
+			m.noVerify = !m.noVerify
+		}
+		return m, nil
+		
+	case key.Matches(msg, m.keys.PrevVersion):
+		m.stepVersion(-1)
+		return m, nil
+		
+	case key.Matches(msg, m.keys.NextVersion):
+		m.stepVersion(1)
+		return m, nil
+		
+	case key.Matches(msg, m.keys.Diff):
+		m.showDiff = !m.showDiff
+		return m, nil
+		
+	case key.Matches(msg, m.keys.Regenerate):
 		m.state = stateGenerating
 		return m, tea.Batch(
 			m.spinner.Tick,
@@ -574,8 +1062,8 @@ func (m *Model) updateReviewing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m *Model) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.selectedMode == modeCustomPrompt {
-		switch msg.Type {
-		case tea.KeyEnter:
+		switch {
+		case key.Matches(msg, m.keys.Enter):
 			m.customPrompt = m.textinput.Value()
 			m.state = stateGenerating
 			return m, tea.Batch(
@@ -583,40 +1071,61 @@ func (m *Model) updateEditing(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.generateCommitMessage(),
 			)
 			
-		case tea.KeyEsc:
+		case key.Matches(msg, m.keys.Back):
 			m.state = stateModeSelection
 			return m, nil
 		}
-		return m, nil
+		
+		var cmd tea.Cmd
+		m.textinput, cmd = m.textinput.Update(msg)
+		return m, cmd
 	}
 	
-	switch msg.Type {
-	case tea.KeyCtrlC:
-		return m, tea.Quit
-		
-	case tea.KeyEsc:
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		// Discarded edits stay in the history
+		m.addVersion(m.textarea.Value())
 		m.state = stateReviewing
+		m.textarea.Blur()
 		m.textarea.SetValue(m.generatedMsg)
+		m.violations = m.validateMessage(m.generatedMsg)
 		return m, nil
 		
-	case tea.KeyCtrlS, tea.KeyCtrlD:
+	case key.Matches(msg, m.keys.ExternalEditor):
+		// Continue in the external editor from the current edits
+		m.addVersion(m.textarea.Value())
+		return m, m.openEditor(m.textarea.Value())
+		
+	case key.Matches(msg, m.keys.Save):
 		m.generatedMsg = m.textarea.Value()
-		return m, m.commitChanges()
+		m.recordVersion(m.generatedMsg)
+		m.textarea.Blur()
+		m.state = stateReviewing
+		return m.commitIfValid()
 	}
 	
-	return m, nil
+	// Lint live while typing
+	var cmd tea.Cmd
+	m.textarea, cmd = m.textarea.Update(msg)
+	m.violations = m.validateMessage(m.textarea.Value())
+	return m, cmd
 }
 
 func (m *Model) updateStagedFilesPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "c":
+	switch {
+	case key.Matches(msg, m.keys.ResumeDraft):
+		if m.resumable != nil {
+			return m.resumeDraft()
+		}
+		
+	case key.Matches(msg, m.keys.Continue):
 		// Continue with already staged files - go straight to mode selection
 		m.selectedFiles = m.alreadyStagedFiles
 		m.setupModeList()
 		m.state = stateModeSelection
 		return m, nil
 		
-	case "u":
+	case key.Matches(msg, m.keys.Unstage):
 		// Unstage all files and start fresh
 		if err := git.UnstageFiles(m.alreadyStagedFiles); err != nil {
 			m.errorMsg = fmt.Sprintf("Failed to unstage files: %v", err)
@@ -627,13 +1136,155 @@ func (m *Model) updateStagedFilesPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.state = stateFileSelection
 		return m, m.loadFiles
 		
-	case "q":
+	case key.Matches(msg, m.keys.Quit):
 		return m, tea.Quit
 	}
 	
 	return m, nil
 }
 
+// switchProfile cycles to the next configured profile for this run
+func (m *Model) switchProfile() (tea.Model, tea.Cmd) {
+	names := append([]string{""}, m.config.ProfileNames()...)
+	next := names[0]
+	for i, name := range names {
+		if name == m.config.ActiveProfile {
+			next = names[(i+1)%len(names)]
+			break
+		}
+	}
+	
+	cfg, err := m.config.WithProfile(next)
+	if err != nil {
+		m.errorMsg = err.Error()
+		m.state = stateError
+		return m, nil
+	}
+	
+	// Profiles can bring their own key command or stored key
+	if apiKey, _, err := cfg.APIKey(); err == nil && apiKey != "" {
+		cfg.OpenAIKey = apiKey
+	} else {
+		cfg.OpenAIKey = m.config.OpenAIKey
+	}
+	
+	m.config = cfg
+	m.openaiClient = newClient(cfg)
+	return m, nil
+}
+
+// stagedDiff returns the diff as sent to the provider, with the files
+// matching llm_exclude reduced to a stat summary. When amending, the diff
+// covers the last commit and the staged changes together.
+func (m *Model) stagedDiff() (string, error) {
+	exclude, err := git.NewPathMatcher(m.config.LLMExclude)
+	if err != nil {
+		return "", err
+	}
+	return m.changes.DiffExcluding(exclude)
+}
+
+// stagedDiffForFile is stagedDiff for a single file
+func (m *Model) stagedDiffForFile(file string) (string, error) {
+	exclude, err := git.NewPathMatcher(m.config.LLMExclude)
+	if err != nil {
+		return "", err
+	}
+	if exclude.Match(file) {
+		summary, err := m.changes.Summary([]string{file})
+		if err != nil {
+			return "", err
+		}
+		return "Diff omitted, summary of the change:\n" + summary, nil
+	}
+	return m.changes.Diff(file)
+}
+
+// secretScanner returns the configured scanner, or nil if scanning is off
+func (m *Model) secretScanner() (*scan.Scanner, error) {
+	if !m.config.SecretScan.Enabled {
+		return nil, nil
+	}
+	return scan.New(m.config.SecretScan)
+}
+
+// newClient creates the API client for the configured provider, or nil if
+// the provider needs a key and none is set
+func newClient(cfg *config.Config) *openai.Client {
+	if cfg.OpenAIKey == "" && cfg.NeedsAPIKey() {
+		return nil
+	}
+	return openai.NewClientWithBaseURL(cfg.OpenAIKey, cfg.Endpoint(), cfg.Model, cfg.Temperature)
+}
+
+func (m *Model) updateSecretsWarning(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Redact):
+		if m.config.SecretScan.Action == scan.ActionBlock {
+			return m, nil
+		}
+		m.redactSecrets = true
+		m.state = stateGenerating
+		return m, tea.Batch(
+			m.spinner.Tick,
+			m.generateCommitMessage(),
+		)
+		
+	case key.Matches(msg, m.keys.Unstage):
+		// A historical commit's changes can't be unstaged
+		if m.reword != "" {
+			return m, nil
+		}
+		files := scan.Files(m.findings)
+		if err := git.UnstageFiles(files); err != nil {
+			m.errorMsg = fmt.Sprintf("Failed to unstage files: %v", err)
+			m.state = stateError
+			return m, nil
+		}
+		m.selectedFiles = without(m.selectedFiles, files)
+		
+		staged, err := m.changes.Files()
+		if err != nil || len(staged) == 0 {
+			m.errorMsg = "No staged changes left after unstaging the affected files"
+			m.state = stateError
+			return m, nil
+		}
+		
+		// Scan again in case other files still contain secrets
+		m.state = stateGenerating
+		return m, tea.Batch(
+			m.spinner.Tick,
+			m.generateCommitMessage(),
+		)
+		
+	case key.Matches(msg, m.keys.Back):
+		m.state = stateModeSelection
+		return m, nil
+		
+	case key.Matches(msg, m.keys.Quit):
+		m.cleanup()
+		return m, tea.Quit
+	}
+	
+	return m, nil
+}
+
+// without returns list minus the given items
+func without(list, remove []string) []string {
+	removed := map[string]bool{}
+	for _, item := range remove {
+		removed[item] = true
+	}
+	
+	var result []string
+	for _, item := range list {
+		if !removed[item] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 // Commands
 func (m *Model) loadFiles() tea.Msg {
 	files, err := git.GetStatus()
@@ -654,7 +1305,7 @@ func (m *Model) checkStagedFiles() tea.Msg {
 	}
 	
 	if len(files) > 0 {
-		return stagedFilesFoundMsg{files: files}
+		return stagedFilesFoundMsg{files: files, draft: m.findDraft()}
 	}
 	
 	return nil
@@ -666,37 +1317,63 @@ func (m *Model) generateCommitMessage() tea.Cmd {
 			return errorMsg{err: fmt.Errorf("OpenAI client not initialized. Please set your API key.")}
 		}
 		
-		var message string
-		var err error
+		// Scan everything that is about to leave the machine. Files excluded
+		// from the prompt are only summarized, so they aren't scanned.
+		scanner, err := m.secretScanner()
+		if err != nil {
+			return errorMsg{err: err}
+		}
+		if scanner != nil && !m.redactSecrets {
+			diff, diffErr := m.stagedDiff()
+			if diffErr != nil {
+				return errorMsg{err: diffErr}
+			}
+			if findings := scanner.Scan(diff); len(findings) > 0 {
+				return secretsFoundMsg{findings: findings}
+			}
+		}
+		prepare := func(diff string) string {
+			if scanner != nil && m.redactSecrets {
+				return scanner.Redact(diff)
+			}
+			return diff
+		}
+		
+		if m.selectedMode == modeSplit {
+			return m.proposeSplit(prepare)
+		}
+		
+		var generated string
 		
 		switch m.selectedMode {
 		case modeAllInOne:
-			diff, diffErr := git.GetStagedDiff()
+			diff, diffErr := m.stagedDiff()
 			if diffErr != nil {
 				return errorMsg{err: diffErr}
 			}
 			
-			message, err = m.openaiClient.GenerateCommitMessage(
-				m.config.SystemPromptAll,
-				diff,
+			generated, err = m.openaiClient.GenerateCommitMessage(
+				m.systemPrompt(m.config.SystemPromptAll),
+				prepare(diff),
 			)
 			
 		case modeByFile:
-			files, filesErr := git.GetStagedFiles()
+			files, filesErr := m.changes.Files()
 			if filesErr != nil {
 				return errorMsg{err: filesErr}
 			}
 			
+			systemPrompt := m.systemPrompt(m.config.SystemPromptFile)
 			var messages []string
 			for _, file := range files {
-				diff, diffErr := git.GetStagedDiffForFile(file)
+				diff, diffErr := m.stagedDiffForFile(file)
 				if diffErr != nil {
 					continue
 				}
 				
 				msg, msgErr := m.openaiClient.GenerateCommitMessage(
-					m.config.SystemPromptFile,
-					diff,
+					systemPrompt,
+					prepare(diff),
 				)
 				if msgErr != nil {
 					continue
@@ -705,53 +1382,383 @@ func (m *Model) generateCommitMessage() tea.Cmd {
 				messages = append(messages, fmt.Sprintf("%s: %s", file, msg))
 			}
 			
-			message = strings.Join(messages, "\n")
+			generated = strings.Join(messages, "\n")
 			
 		case modeCustomPrompt:
-			diff, diffErr := git.GetStagedDiff()
+			diff, diffErr := m.stagedDiff()
 			if diffErr != nil {
 				return errorMsg{err: diffErr}
 			}
 			
-			message, err = m.openaiClient.GenerateCommitMessageWithContext(
-				m.config.SystemPromptAll,
-				diff,
+			generated, err = m.openaiClient.GenerateCommitMessageWithContext(
+				m.systemPrompt(m.config.SystemPromptAll),
+				prepare(diff),
 				m.customPrompt,
 			)
+
+		case modeConventional:
+			diff, diffErr := m.stagedDiff()
+			if diffErr != nil {
+				return errorMsg{err: diffErr}
+			}
+
+			files, filesErr := m.changes.Files()
+			if filesErr != nil {
+				return errorMsg{err: filesErr}
+			}
+
+			scope := message.InferScope(files, m.config.ConventionalScopes)
+			generated, err = m.openaiClient.GenerateCommitMessageWithContext(
+				m.systemPrompt(m.conventionalSystemPrompt()),
+				prepare(diff),
+				conventionalContext(files, scope),
+			)
+			generated = message.ApplyScope(generated, scope)
+		}
+		
+		if err != nil {
+			return errorMsg{err: err}
 		}
 		
+		generated, err = m.applyTickets(generated)
 		if err != nil {
 			return errorMsg{err: err}
 		}
 		
-		return commitMessageGeneratedMsg{message: message}
+		return commitMessageGeneratedMsg{message: generated}
 	}
 }
 
 func (m *Model) commitChanges() tea.Cmd {
-	return func() tea.Msg {
-		message := m.textarea.Value()
-		if message == "" {
-			message = m.generatedMsg
+	message := m.textarea.Value()
+	if message == "" {
+		message = m.generatedMsg
+	}
+	opts := m.commitOptions()
+	
+	// Rewording runs no hooks, so there is nothing to stream
+	if m.reword != "" {
+		return func() tea.Msg {
+			message, err := m.finalMessage(message)
+			if err != nil {
+				return errorMsg{err: err}
+			}
+			if err := git.Reword(m.reword, message, opts); err != nil {
+				return errorMsg{err: err}
+			}
+			// A reworded commit isn't HEAD, so only describe where HEAD is
+			return commitSuccessMsg{summary: summarizeCommits(0)}
+		}
+	}
+	
+	ch := m.startCommitLog()
+	go func() {
+		message, err := m.finalMessage(message)
+		if err != nil {
+			ch <- errorMsg{err: err}
+			return
 		}
 		
-		if err := git.Commit(message); err != nil {
+		// Remember the staged files' contents to tell whether hooks
+		// such as formatters changed them
+		staged, _ := git.GetStagedFiles()
+		before, _ := git.HashWorktreeFiles(staged)
+		
+		err = git.CommitStreaming(message, opts, func(line string, replace bool) {
+			ch <- commitOutputMsg{line: line, replace: replace}
+		})
+		if err != nil {
+			after, _ := git.HashWorktreeFiles(staged)
+			ch <- commitFailedMsg{err: err, modified: changedFiles(before, after), before: before}
+			return
+		}
+		
+		ch <- commitSuccessMsg{summary: summarizeCommits(1)}
+	}()
+	
+	return waitForMsg(ch)
+}
+
+// commitOptions resolves signing and hook settings for this run. The auto
+// mode passes commit.gpgSign on explicitly because commit-tree, used for
+// rewording, doesn't read it.
+func (m *Model) commitOptions() git.CommitOptions {
+	opts := git.CommitOptions{
+		Amend:      m.amend,
+		SigningKey: m.config.SigningKey,
+		NoVerify:   m.noVerify,
+	}
+	if m.signingKey != "" {
+		opts.SigningKey = m.signingKey
+	}
+	
+	mode := m.config.Sign
+	if m.signMode != "" {
+		mode = m.signMode
+	}
+	switch mode {
+	case config.SignAlways:
+		opts.Sign = true
+	case config.SignNever:
+		opts.NoSign = true
+	default:
+		opts.Sign = m.signing.Enabled
+		opts.NoSign = !m.signing.Enabled
+	}
+	
+	return opts
+}
+
+// lintRules returns the linter rules for the selected mode. By-file
+// messages are one "file: message" line per file rather than a subject
+// and body, so the body and mood rules don't apply to them.
+func (m *Model) lintRules() message.Rules {
+	rules := m.config.Lint
+	if m.selectedMode == modeByFile {
+		rules.ImperativeMood = false
+		rules.BlankLineAfterSubject = false
+		rules.BodyWrapWidth = 0
+	}
+	return rules
+}
+
+// validateMessage runs the linter and the validators that apply to the
+// selected mode. Errors from non-strict checks are reported as warnings.
+func (m *Model) validateMessage(msg string) []message.Violation {
+	msg = m.stripTemplateComments(msg)
+	violations := message.Lint(msg, m.lintRules())
+	if !m.config.Lint.Strict {
+		violations = message.AsWarnings(violations)
+	}
+
+	if m.selectedMode == modeConventional {
+		conventional := message.ValidateConventional(msg, m.config.ConventionalTypes)
+		if !m.config.ConventionalStrict {
+			conventional = message.AsWarnings(conventional)
+		}
+		violations = append(conventional, violations...)
+	}
+
+	return violations
+}
+
+// commitBlocked reports whether the current violations prevent committing
+func (m *Model) commitBlocked() bool {
+	return message.HasErrors(m.violations)
+}
+
+// fixMessage applies the linter's automatic fixes to the reviewed message
+func (m *Model) fixMessage() {
+	fixed := message.Fix(m.textarea.Value(), m.lintRules())
+	m.textarea.SetValue(fixed)
+	m.violations = m.validateMessage(fixed)
+	m.recordVersion(fixed)
+	m.saveDraft()
+}
+
+// commitIfValid validates the reviewed message and commits it unless the
+// violations block the commit, in which case the review screen lists them
+func (m *Model) commitIfValid() (tea.Model, tea.Cmd) {
+	m.violations = m.validateMessage(m.textarea.Value())
+	m.saveDraft()
+	if m.commitBlocked() {
+		return m, nil
+	}
+	return m, m.commitChanges()
+}
+
+// systemPrompt extends a mode's system prompt with the tickets from the
+// branch name and recent commit messages from the repository, so generated
+// messages follow the existing conventions
+func (m *Model) systemPrompt(prompt string) string {
+	if m.amend && m.lastMsg != "" {
+		prompt += "\n\nThe changes amend an existing commit. Update its message to describe all of the changes, " +
+			"keeping what still applies. The current message is:\n\n" + m.lastMsg
+	} else if m.reword != "" && m.lastMsg != "" {
+		prompt += "\n\nThe changes are an existing commit whose message is being rewritten. " +
+			"Keep any details from the current message that the diff doesn't show. The current message is:\n\n" + m.lastMsg
+	}
+	
+	prompt += m.templatePrompt()
+	
+	if tickets := m.branchTickets(); len(tickets) > 0 {
+		prompt += fmt.Sprintf("\n\nThese changes belong to %s. Don't add ticket references yourself, they are added automatically.",
+			strings.Join(tickets, ", "))
+	}
+	
+	if m.config.StyleExamples <= 0 {
+		return prompt
+	}
+	
+	var paths []string
+	if m.config.StyleExamplesByPath {
+		paths, _ = m.changes.Files()
+	}
+	
+	examples, err := git.GetRecentCommitMessages(m.config.StyleExamples, paths)
+	if err != nil || len(examples) == 0 {
+		return prompt
+	}
+	
+	return prompt + "\n\nMatch the style of these recent commit messages from this repository " +
+		"(tense, prefixes, ticket references, casing and length), but describe only the new changes:\n\n" +
+		strings.Join(examples, "\n---\n")
+}
+
+// branchTickets returns the ticket references in the current branch name
+func (m *Model) branchTickets() []string {
+	branch, err := git.GetCurrentBranch()
+	if err != nil || branch == "" {
+		return nil
+	}
+	
+	tickets, err := message.ExtractTickets(branch, m.config.TicketPattern)
+	if err != nil {
+		return nil
+	}
+	return tickets
+}
+
+// applyTickets adds the branch's tickets to a generated message as a
+// subject prefix or as trailers, depending on ticket_placement
+func (m *Model) applyTickets(msg string) (string, error) {
+	tickets := m.branchTickets()
+	if len(tickets) == 0 {
+		return msg, nil
+	}
+	
+	switch m.config.TicketPlacement {
+	case message.TicketPrefix:
+		return message.AddTicketPrefix(msg, tickets), nil
+		
+	case message.TicketTrailer:
+		key := m.config.TicketTrailer
+		if key == "" {
+			key = "Refs"
+		}
+		var trailers []string
+		for _, ticket := range tickets {
+			trailers = append(trailers, key+": "+ticket)
+		}
+		return git.InterpretTrailers(msg, trailers)
+	}
+	
+	return msg, nil
+}
+
+func (m *Model) conventionalSystemPrompt() string {
+	types := m.config.ConventionalTypes
+	if len(types) == 0 {
+		types = message.DefaultTypes
+	}
+	return fmt.Sprintf("%s Allowed types: %s.", m.config.SystemPromptConventional, strings.Join(types, ", "))
+}
+
+// conventionalContext describes the inferred type and scope for the prompt
+func conventionalContext(files []string, scope string) string {
+	var hints []string
+	if t := message.InferType(files); t != "" {
+		hints = append(hints, fmt.Sprintf("The changes only touch %s files, so use the type %q.", t, t))
+	}
+	if scope != "" {
+		hints = append(hints, fmt.Sprintf("Use the scope %q.", scope))
+	} else {
+		hints = append(hints, "Omit the scope.")
+	}
+	hints = append(hints, "Changed files: "+strings.Join(files, ", "))
+	return strings.Join(hints, " ")
+}
+
+// loadTarget loads the commit being amended or reworded and the changes
+// its message describes
+func (m *Model) loadTarget() tea.Msg {
+	rev := "HEAD"
+	if m.reword != "" {
+		rev = m.reword
+	}
+	
+	commit, err := git.ResolveCommit(rev)
+	if err != nil {
+		return errorMsg{err: err}
+	}
+	
+	msg, err := git.GetCommitMessage(commit)
+	if err != nil {
+		return errorMsg{err: err}
+	}
+	
+	parent, err := git.ParentOf(commit)
+	if err != nil {
+		return errorMsg{err: err}
+	}
+	
+	changes := git.Changes{Base: parent}
+	if m.reword != "" {
+		changes.Commit = commit
+		
+		if ok, err := git.IsAncestorOfHead(commit); err != nil {
 			return errorMsg{err: err}
+		} else if !ok {
+			return errorMsg{err: fmt.Errorf("%s is not on the current branch", rev)}
 		}
 		
-		return commitSuccessMsg{}
+		if dirty, err := git.HasUncommittedChanges(); err != nil {
+			return errorMsg{err: err}
+		} else if dirty {
+			return errorMsg{err: fmt.Errorf("commit or stash your changes before rewording %s", rev)}
+		}
 	}
+	
+	pushed, upstream, err := git.IsPushed(commit)
+	if err != nil {
+		return errorMsg{err: err}
+	}
+	if !pushed {
+		upstream = ""
+	}
+	
+	return targetLoadedMsg{message: msg, changes: changes, pushedTo: upstream}
 }
 
 // Add cleanup command
 func (m *Model) cleanup() tea.Msg {
-	// Only unstage files if we staged them in this session and didn't commit
-	if len(m.selectedFiles) > 0 && m.state != stateSuccess {
-		git.UnstageFiles(m.selectedFiles)
+	// Keep an interrupted message as a draft. It is keyed to the staged
+	// tree, so staging the same changes again offers it for resuming.
+	switch m.state {
+	case stateReviewing, stateEditing, stateCommitting, stateTrailers:
+		m.saveDraft()
+	}
+	
+	if m.state != stateSuccess {
+		m.restoreIndex()
 	}
 	return nil
 }
 
+// snapshotIndex records the index so cleanup can put back exactly what was
+// staged, including partially staged files
+func (m *Model) snapshotIndex() {
+	// write-tree fails while there are conflicts, which cleanup then leaves
+	// alone rather than lose the merge state
+	m.indexTree, _ = git.WriteTree()
+	m.indexHead, _ = git.ResolveCommit("HEAD")
+}
+
+// restoreIndex puts the index back to the snapshot. Once HEAD has moved,
+// the snapshot would undo the new commits in the index, so it is kept as is.
+func (m *Model) restoreIndex() {
+	if m.indexTree == "" {
+		return
+	}
+	if head, _ := git.ResolveCommit("HEAD"); head != m.indexHead {
+		return
+	}
+	if tree, err := git.WriteTree(); err == nil && tree == m.indexTree {
+		return
+	}
+	git.ReadTree(m.indexTree)
+}
+
 // Message types
 type filesLoadedMsg struct {
 	files []git.File
@@ -765,7 +1772,24 @@ type commitMessageGeneratedMsg struct {
 	message string
 }
 
-type commitSuccessMsg struct{}
+type commitSuccessMsg struct {
+	summary commitSummary
+}
+
+type editorClosedMsg struct {
+	path        string
+	commentChar string // Comment character used in the file
+	err         error
+}
+
+type previewLoadedMsg struct {
+	path string
+	diff string
+}
+
+type reviewDiffLoadedMsg struct {
+	diff string
+}
 
 type errorMsg struct {
 	err error
@@ -773,4 +1797,46 @@ type errorMsg struct {
 
 type stagedFilesFoundMsg struct {
 	files []string
-} 
\ No newline at end of file
+	draft *draft.Draft // Uncommitted draft for exactly these files, if any
+}
+
+type secretsFoundMsg struct {
+	findings []scan.Finding
+} 
+
+type targetLoadedMsg struct {
+	message  string
+	changes  git.Changes
+	pushedTo string
+}
+
+type splitProposedMsg struct {
+	hunks  []git.Hunk
+	groups []splitGroup
+}
+
+type splitCommittedMsg struct {
+	count   int
+	summary commitSummary
+}
+
+type commitOutputMsg struct {
+	line    string
+	replace bool
+}
+
+type commitFailedMsg struct {
+	err       error
+	modified  []string          // Files changed by hooks
+	before    map[string]string // Blobs of the staged files before the hooks
+	committed int               // Split commits made before the failure
+}
+
+type pushOutputMsg struct {
+	line    string
+	replace bool
+}
+
+type pushDoneMsg struct {
+	err error
+}
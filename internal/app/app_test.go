@@ -0,0 +1,186 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/oconnorjohnson/add-n-commit/internal/config"
+)
+
+// newTestRepo makes the working directory a new repository with one commit
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	gitRun(t, "init", "-q")
+	gitRun(t, "config", "user.name", "Test")
+	gitRun(t, "config", "user.email", "test@example.com")
+	writeFile(t, "README.md", "hello\n")
+	gitRun(t, "add", "README.md")
+	gitRun(t, "commit", "-q", "-m", "Initial commit")
+	return dir
+}
+
+func gitRun(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(".", name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestModel creates a model that needs no API key
+func newTestModel(t *testing.T) *Model {
+	t.Helper()
+	cfg := config.Default()
+	cfg.OpenAIKey = "sk-test"
+	return New(cfg)
+}
+
+// isQuit reports whether cmd quits the program
+func isQuit(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func TestQuitWaitsForRunningCommit(t *testing.T) {
+	tests := []struct {
+		name   string
+		done   tea.Msg
+		staged string
+	}{
+		{
+			name:   "failed commit restores the index",
+			done:   commitFailedMsg{err: errors.New("pre-commit hook failed")},
+			staged: "",
+		},
+		{
+			name:   "successful commit keeps the index",
+			done:   commitSuccessMsg{},
+			staged: "main.go\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestRepo(t)
+			m := newTestModel(t)
+
+			// The app staged a file and started git commit
+			writeFile(t, "main.go", "package main\n")
+			gitRun(t, "add", "main.go")
+			m.startCommitLog()
+
+			_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+			if isQuit(cmd) {
+				t.Fatal("Ctrl+C quit while git commit was running")
+			}
+			if got := gitRun(t, "diff", "--cached", "--name-only"); got != "main.go\n" {
+				t.Fatalf("index was restored under a running commit, staged = %q", got)
+			}
+
+			_, cmd = m.Update(tt.done)
+			if !isQuit(cmd) {
+				t.Fatal("didn't quit after the commit finished")
+			}
+			if got := gitRun(t, "diff", "--cached", "--name-only"); got != tt.staged {
+				t.Errorf("staged = %q, want %q", got, tt.staged)
+			}
+		})
+	}
+}
+
+func TestFileSelectionWithoutFiles(t *testing.T) {
+	keys := []tea.KeyMsg{
+		{Type: tea.KeyEnter},
+		{Type: tea.KeySpace, Runes: []rune{' '}},
+		{Type: tea.KeyRunes, Runes: []rune{'a'}},
+		{Type: tea.KeyRunes, Runes: []rune{'t'}},
+		{Type: tea.KeyRunes, Runes: []rune{'h'}},
+		{Type: tea.KeyRunes, Runes: []rune{'l'}},
+		{Type: tea.KeyLeft},
+		{Type: tea.KeyRight},
+	}
+
+	tests := []struct {
+		name string
+		load bool
+	}{
+		{"clean repository", true},
+		{"before files are loaded", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestRepo(t)
+			m := newTestModel(t)
+			if tt.load {
+				m.Update(m.loadFiles())
+			}
+
+			for _, k := range keys {
+				if _, cmd := m.Update(k); isQuit(cmd) {
+					t.Fatalf("%q quit", k.String())
+				}
+				if m.state != stateFileSelection {
+					t.Fatalf("%q left file selection for state %d", k.String(), m.state)
+				}
+			}
+			m.View()
+		})
+	}
+}
+
+func TestFilesLoadedRebuildsTree(t *testing.T) {
+	newTestRepo(t)
+	m := newTestModel(t)
+
+	writeFile(t, "a.go", "package a\n")
+	writeFile(t, "b.go", "package b\n")
+	m.Update(m.loadFiles())
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if got := strings.Join(m.fileTree.Selected(), ","); got != "a.go,b.go" {
+		t.Fatalf("selected = %q after toggling all", got)
+	}
+
+	// A session reloads the files after a commit
+	gitRun(t, "add", "a.go")
+	gitRun(t, "commit", "-q", "-m", "Add a")
+	m.Update(m.loadFiles())
+	if len(m.fileTree.Selected()) != 0 {
+		t.Errorf("selected = %v after reloading, want none", m.fileTree.Selected())
+	}
+	if n := len(m.fileList.Items()); n != 1 {
+		t.Errorf("file list has %d rows after reloading, want 1", n)
+	}
+
+	// Everything is committed
+	gitRun(t, "add", "b.go")
+	gitRun(t, "commit", "-q", "-m", "Add b")
+	m.Update(m.loadFiles())
+	if n := len(m.fileList.Items()); n != 0 {
+		t.Errorf("file list has %d rows with no changes, want 0", n)
+	}
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); isQuit(cmd) || m.state != stateFileSelection {
+		t.Errorf("Enter with no changes left file selection")
+	}
+}
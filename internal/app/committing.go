@@ -0,0 +1,179 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+// commitLogLines caps how much commit output is kept for scrolling
+const commitLogLines = 2000
+
+// startCommitLog switches to the committing screen with an empty log and
+// returns the channel the commit command reports on
+func (m *Model) startCommitLog() chan tea.Msg {
+	ch := make(chan tea.Msg)
+
+	m.commitLog = nil
+	m.commitView.SetContent("")
+	m.commitErr = ""
+	m.hookModified = nil
+	m.committing = true
+	m.commitCh = ch
+	m.state = stateCommitting
+
+	return ch
+}
+
+func (m *Model) addCommitOutput(msg commitOutputMsg) {
+	if msg.replace && len(m.commitLog) > 0 {
+		m.commitLog[len(m.commitLog)-1] = msg.line
+	} else {
+		m.commitLog = append(m.commitLog, msg.line)
+	}
+	if len(m.commitLog) > commitLogLines {
+		m.commitLog = m.commitLog[len(m.commitLog)-commitLogLines:]
+	}
+
+	m.commitView.SetContent(strings.Join(m.commitLog, "\n"))
+	m.commitView.GotoBottom()
+}
+
+// changedFiles returns the files whose hash differs between two snapshots
+// or that disappeared, sorted
+func changedFiles(before, after map[string]string) []string {
+	var changed []string
+	for file, hash := range before {
+		if after[file] != hash {
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// quitAfterCommit quits once the commit that was running when Ctrl+C was
+// pressed has finished. A commit that went through is kept, otherwise the
+// index is restored like any other quit.
+func (m *Model) quitAfterCommit(committed bool) (tea.Model, tea.Cmd) {
+	if committed {
+		m.state = stateSuccess
+	}
+	m.cleanup()
+	return m, tea.Quit
+}
+
+func (m *Model) updateCommitting(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.committing {
+		var cmd tea.Cmd
+		m.commitView, cmd = m.commitView.Update(msg)
+		return m, cmd
+	}
+
+	split := m.selectedMode == modeSplit
+
+	switch {
+	case key.Matches(msg, m.keys.Retry):
+		return m, m.retryCommit()
+
+	case key.Matches(msg, m.keys.Restage):
+		if len(m.hookModified) == 0 || split {
+			return m, nil
+		}
+		// Only the hooks' changes, unstaged changes stay unstaged
+		if err := git.RestageChanges(m.hookModified, m.hookBefore); err != nil {
+			m.commitErr = err.Error()
+			return m, nil
+		}
+		// The draft now belongs to the re-staged tree
+		m.draftTree = ""
+		m.saveDraft()
+		return m, m.retryCommit()
+
+	case key.Matches(msg, m.keys.Edit):
+		if split {
+			m.state = stateSplit
+			return m.editSplitMessage()
+		}
+		m.state = stateEditing
+		m.textarea.Focus()
+		return m, textarea.Blink
+
+	case key.Matches(msg, m.keys.Back):
+		if split {
+			m.state = stateSplit
+		} else {
+			m.state = stateReviewing
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.Quit):
+		m.cleanup()
+		return m, tea.Quit
+	}
+
+	var cmd tea.Cmd
+	m.commitView, cmd = m.commitView.Update(msg)
+	return m, cmd
+}
+
+// retryCommit runs the failed commit again with the same message
+func (m *Model) retryCommit() tea.Cmd {
+	if m.selectedMode == modeSplit {
+		return m.commitSplit()
+	}
+	return m.commitChanges()
+}
+
+func (m *Model) viewCommitting() string {
+	title := "Committing"
+	if m.commitErr != "" {
+		title = "Commit failed"
+	}
+
+	logBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Render(m.commitView.View())
+
+	if m.committing {
+		status := "Running git commit and hooks..."
+		if m.quitPending {
+			status = "Quitting once git commit finishes..."
+		}
+		return fmt.Sprintf(
+			"%s\n\n%s\n\n%s",
+			ui.Title(title),
+			logBox,
+			ui.Subtle(status)+"\n"+m.viewHelp(),
+		)
+	}
+
+	// The full output is in the log, the first line says what failed
+	reason := m.commitErr
+	if idx := strings.IndexByte(reason, '\n'); idx >= 0 {
+		reason = reason[:idx]
+	}
+
+	var b strings.Builder
+	b.WriteString(ui.ErrorStyle.Render(reason))
+
+	if m.selectedMode != modeSplit && len(m.hookModified) > 0 {
+		b.WriteString("\n\n" + ui.WarningStyle.Render("Hooks modified: "+strings.Join(m.hookModified, ", ")))
+	}
+
+	return fmt.Sprintf(
+		"%s\n\n%s\n\n%s\n\n%s",
+		ui.Title(title),
+		logBox,
+		b.String(),
+		m.viewHelp(),
+	)
+}
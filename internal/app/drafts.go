@@ -0,0 +1,258 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/oconnorjohnson/add-n-commit/internal/draft"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/message"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+// modeNames identify the generation mode of a saved draft
+var modeNames = map[commitMode]string{
+	modeAllInOne:     "all",
+	modeByFile:       "by-file",
+	modeCustomPrompt: "custom",
+	modeConventional: "conventional",
+	modeSplit:        "split",
+}
+
+func modeFromName(name string) commitMode {
+	for mode, n := range modeNames {
+		if n == name {
+			return mode
+		}
+	}
+	return modeAllInOne
+}
+
+// draftsEnabled reports whether messages are saved as drafts. Amending,
+// rewording and splitting don't describe the index alone, so they aren't.
+func (m *Model) draftsEnabled() bool {
+	return m.config.DraftHistory > 0 && !m.amend && m.reword == "" && m.selectedMode != modeSplit
+}
+
+func (m *Model) draftStore() *draft.Store {
+	gitDir, err := git.GetGitDir()
+	if err != nil {
+		return nil
+	}
+	return draft.Open(gitDir, m.config.DraftHistory)
+}
+
+// saveDraft saves the message under review for what is staged. Drafts are
+// best effort and never get in the way of committing.
+func (m *Model) saveDraft() {
+	if !m.draftsEnabled() {
+		return
+	}
+
+	msg := m.textarea.Value()
+	if msg == "" {
+		msg = m.generatedMsg
+	}
+	if strings.TrimSpace(msg) == "" {
+		return
+	}
+
+	store := m.draftStore()
+	if store == nil {
+		return
+	}
+
+	if m.draftTree == "" {
+		tree, err := git.WriteTree()
+		if err != nil {
+			return
+		}
+		m.draftTree = tree
+	}
+
+	branch, _ := git.GetCurrentBranch()
+	err := store.Save(draft.Draft{
+		Tree:    m.draftTree,
+		Message: msg,
+		Mode:    modeNames[m.selectedMode],
+		Branch:  branch,
+	})
+	m.draftSaved = err == nil
+}
+
+// markDraftCommitted keeps the committed message in the draft history
+// without offering it for resuming again
+func (m *Model) markDraftCommitted() {
+	defer func() {
+		m.draftTree = ""
+		m.draftSaved = false
+	}()
+
+	if !m.draftSaved {
+		return
+	}
+	store := m.draftStore()
+	if store == nil {
+		return
+	}
+	d, err := store.Load(m.draftTree)
+	if err != nil || d == nil {
+		return
+	}
+	d.Committed = true
+	d.Saved = time.Now()
+	store.Save(*d)
+}
+
+// findDraft returns the uncommitted draft saved for exactly what is
+// staged now, if any
+func (m *Model) findDraft() *draft.Draft {
+	if m.config.DraftHistory <= 0 {
+		return nil
+	}
+	store := m.draftStore()
+	if store == nil {
+		return nil
+	}
+	tree, err := git.WriteTree()
+	if err != nil {
+		return nil
+	}
+	d, err := store.Load(tree)
+	if err != nil || d == nil || d.Committed {
+		return nil
+	}
+	return d
+}
+
+// resumeDraft continues with the staged files and the saved message
+func (m *Model) resumeDraft() (tea.Model, tea.Cmd) {
+	d := m.resumable
+	m.resumable = nil
+
+	m.selectedFiles = m.alreadyStagedFiles
+	m.selectedMode = modeFromName(d.Mode)
+	m.generatedMsg = d.Message
+	m.draftTree = d.Tree
+	m.draftSaved = true
+
+	m.setupModeList()
+	m.textarea.SetValue(d.Message)
+	m.recordVersion(d.Message)
+	m.violations = m.validateMessage(d.Message)
+	m.state = stateReviewing
+	m.reviewDiff = ""
+	return m, m.loadPreview()
+}
+
+// viewResumableDraft describes the draft offered in the staged files prompt
+func (m *Model) viewResumableDraft() string {
+	if m.resumable == nil {
+		return ""
+	}
+
+	saved := m.resumable.Saved.Format("Jan 2 15:04")
+	if m.resumable.Branch != "" {
+		saved += " on " + m.resumable.Branch
+	}
+	return fmt.Sprintf("\n\n%s\n  %s",
+		ui.SuccessStyle.Render(fmt.Sprintf("A draft message was saved for exactly these changes (%s):", saved)),
+		message.Subject(m.resumable.Message),
+	)
+}
+
+// draftPickerEnabled reports whether recent drafts can be picked for the
+// staged changes
+func (m *Model) draftPickerEnabled() bool {
+	return m.config.DraftHistory > 0 && !m.amend && m.reword == ""
+}
+
+// openDraftPicker lists the recent drafts, newest first, including
+// committed ones
+func (m *Model) openDraftPicker() {
+	if !m.draftPickerEnabled() {
+		return
+	}
+
+	m.drafts = nil
+	if store := m.draftStore(); store != nil {
+		m.drafts, _ = store.List()
+	}
+
+	items := make([]list.Item, len(m.drafts))
+	for i, d := range m.drafts {
+		detail := d.Saved.Format("Jan 2 15:04")
+		if d.Branch != "" {
+			detail += " on " + d.Branch
+		}
+		if d.Committed {
+			detail += ", committed"
+		}
+		items[i] = ui.DraftItem{Subject: message.Subject(d.Message), Detail: detail, Index: i}
+	}
+	m.draftList.SetItems(items)
+	m.draftList.Select(0)
+	m.state = stateDrafts
+}
+
+func (m *Model) updateDrafts(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Quit):
+		m.cleanup()
+		return m, tea.Quit
+
+	case key.Matches(msg, m.keys.Back):
+		m.state = stateModeSelection
+		return m, nil
+
+	case key.Matches(msg, m.keys.Enter):
+		if i, ok := m.draftList.SelectedItem().(ui.DraftItem); ok {
+			return m.useDraft(m.drafts[i.Index])
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.draftList, cmd = m.draftList.Update(msg)
+	return m, cmd
+}
+
+// useDraft reviews a recent draft's message for what is staged now. It is
+// saved again as a draft of the current staged tree.
+func (m *Model) useDraft(d draft.Draft) (tea.Model, tea.Cmd) {
+	m.selectedMode = modeFromName(d.Mode)
+	m.generatedMsg = d.Message
+	m.draftTree = ""
+	m.draftSaved = false
+
+	m.textarea.SetValue(d.Message)
+	m.recordVersion(d.Message)
+	m.violations = m.validateMessage(d.Message)
+	m.saveDraft()
+	m.state = stateReviewing
+	m.reviewDiff = ""
+	return m, m.loadPreview()
+}
+
+func (m *Model) viewDrafts() string {
+	if len(m.drafts) == 0 {
+		return fmt.Sprintf(
+			"%s\n\n%s\n\n%s",
+			ui.Title("Recent drafts"),
+			ui.Subtle("No drafts saved yet"),
+			m.viewHelp(),
+		)
+	}
+
+	return fmt.Sprintf(
+		"%s\n%s\n\n%s\n\n%s",
+		ui.Title("Recent drafts"),
+		ui.Subtle("Pick a message to review for the staged changes"),
+		m.draftList.View(),
+		m.viewHelp(),
+	)
+}
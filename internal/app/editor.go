@@ -0,0 +1,95 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/message"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+// openEditor suspends the TUI and opens msg in git's editor, with the diff
+// below it as comments like git's verbose commit template
+func (m *Model) openEditor(msg string) tea.Cmd {
+	m.editorErr = ""
+
+	editor, err := git.GetEditor()
+	if err != nil {
+		m.editorErr = err.Error()
+		return nil
+	}
+	gitDir, err := git.GetGitDir()
+	if err != nil {
+		m.editorErr = err.Error()
+		return nil
+	}
+
+	// Named like git's own file so editors switch to their commit message
+	// mode
+	dir := filepath.Join(gitDir, "anc")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		m.editorErr = fmt.Sprintf("Failed to create %s: %v", dir, err)
+		return nil
+	}
+	path := filepath.Join(dir, "COMMIT_EDITMSG")
+
+	// The diff is only a reference, the message can be edited without it.
+	// Comments use a character no line of the message starts with, so a
+	// "#512: ..." subject isn't stripped when it is read back.
+	diff, _ := m.changes.Diff()
+	commentChar := message.CommentCharFor(msg, m.commentChar)
+	if err := os.WriteFile(path, []byte(message.EditTemplate(msg, diff, commentChar)), 0600); err != nil {
+		m.editorErr = fmt.Sprintf("Failed to write message file: %v", err)
+		return nil
+	}
+
+	// Run the editor through the shell like git does, so commands with
+	// arguments such as "code --wait" work
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorClosedMsg{path: path, commentChar: commentChar, err: err}
+	})
+}
+
+// editorClosed reads the message back from the editor into the review
+func (m *Model) editorClosed(msg editorClosedMsg) (tea.Model, tea.Cmd) {
+	defer os.Remove(msg.path)
+
+	m.state = stateReviewing
+	m.textarea.Blur()
+	m.textarea.SetValue(m.generatedMsg)
+
+	if msg.err != nil {
+		m.editorErr = fmt.Sprintf("Editor failed: %v", msg.err)
+		return m, nil
+	}
+	data, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.editorErr = fmt.Sprintf("Failed to read message file: %v", err)
+		return m, nil
+	}
+
+	edited := message.StripComments(string(data), msg.commentChar)
+	if edited == "" {
+		m.editorErr = "The edited message was empty, kept the previous one"
+		return m, nil
+	}
+
+	m.generatedMsg = edited
+	m.textarea.SetValue(edited)
+	m.violations = m.validateMessage(edited)
+	m.recordVersion(edited)
+	m.saveDraft()
+	return m, nil
+}
+
+func (m *Model) viewEditorError() string {
+	if m.editorErr == "" {
+		return ""
+	}
+	return "\n" + ui.ErrorStyle.Render(m.editorErr)
+}
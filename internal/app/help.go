@@ -0,0 +1,245 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/oconnorjohnson/add-n-commit/internal/scan"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+// screenKeys are the bindings of one screen for the help component. The
+// help line shows the main actions, the ? overlay all of them.
+type screenKeys struct {
+	nav   []key.Binding // Moving around, only in the overlay
+	short []key.Binding // Shown in the help line
+	more  []key.Binding // Other actions, only in the overlay
+	help  key.Binding
+}
+
+func (s screenKeys) ShortHelp() []key.Binding {
+	if len(s.nav) == 0 && len(s.more) == 0 {
+		return s.short
+	}
+	return append(append([]key.Binding(nil), s.short...), s.help)
+}
+
+func (s screenKeys) FullHelp() [][]key.Binding {
+	var columns [][]key.Binding
+	for _, column := range [][]key.Binding{s.nav, s.short, s.more} {
+		if len(column) > 0 {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// withHelp returns a copy of a binding with another description
+func withHelp(b key.Binding, desc string) key.Binding {
+	b.SetHelp(b.Help().Key, desc)
+	return b
+}
+
+// enabledIf returns a copy of a binding that is only shown and matched
+// when enabled is set
+func enabledIf(b key.Binding, enabled bool) key.Binding {
+	if !enabled {
+		b.SetEnabled(false)
+	}
+	return b
+}
+
+// typing reports whether keys go to a text input, where plain keys such as
+// ? are text
+func (m *Model) typing() bool {
+	switch m.state {
+	case stateConfig, stateEditing:
+		return true
+	case stateTrailers:
+		return m.trailerView == trailerCustom
+	case stateSplit:
+		return m.splitEditing
+	}
+	return false
+}
+
+// screenKeys returns the bindings that apply to the current screen
+func (m *Model) screenKeys() screenKeys {
+	k := m.keys
+	s := screenKeys{help: k.Help}
+	if m.typing() {
+		s.help.SetEnabled(false)
+	}
+
+	switch m.state {
+	case stateConfig:
+		s.short = []key.Binding{withHelp(k.Enter, "save"), withHelp(k.Back, "quit")}
+
+	case stateFileSelection:
+		if len(m.files) == 0 || m.fileTree == nil {
+			s.short = []key.Binding{k.Quit}
+			break
+		}
+		s.nav = []key.Binding{k.Up, k.Down}
+		s.short = []key.Binding{k.Toggle, k.ToggleAll, withHelp(k.Enter, "continue"), k.Quit}
+		s.more = []key.Binding{k.TreeView}
+		if !m.fileTree.Flat() {
+			s.nav = append(s.nav, withHelp(k.Left, "collapse/parent"), withHelp(k.Right, "expand"))
+		}
+
+	case stateModeSelection:
+		s.nav = []key.Binding{k.Up, k.Down}
+		s.short = []key.Binding{k.Enter, enabledIf(k.Profile, len(m.config.Profiles) > 0), k.Quit}
+		s.more = []key.Binding{enabledIf(k.Drafts, m.draftPickerEnabled())}
+
+	case stateDrafts:
+		s.nav = []key.Binding{k.Up, k.Down}
+		s.short = []key.Binding{withHelp(k.Enter, "use message"), k.Back, k.Quit}
+
+	case stateReviewing:
+		versions := len(m.history) > 1
+		s.short = []key.Binding{withHelp(k.Enter, "commit"), k.Edit, k.Regenerate, k.Quit}
+		s.more = []key.Binding{
+			k.OpenEditor,
+			k.Fix,
+			k.Trailers,
+			enabledIf(k.ToggleHooks, m.reword == ""), // Rewording runs no hooks
+			enabledIf(k.PrevVersion, versions),
+			enabledIf(k.NextVersion, versions),
+			enabledIf(k.Diff, versions),
+		}
+
+	case stateEditing:
+		if m.selectedMode == modeCustomPrompt {
+			s.short = []key.Binding{withHelp(k.Enter, "generate message"), k.Back}
+			break
+		}
+		s.short = []key.Binding{withHelp(k.Save, "commit"), k.ExternalEditor, withHelp(k.Back, "cancel")}
+
+	case stateStagedFilesPrompt:
+		s.short = []key.Binding{
+			enabledIf(k.ResumeDraft, m.resumable != nil),
+			k.Continue,
+			withHelp(k.Unstage, "unstage and start fresh"),
+			k.Quit,
+		}
+
+	case stateSecretsWarning:
+		s.short = []key.Binding{
+			enabledIf(k.Redact, m.config.SecretScan.Action != scan.ActionBlock),
+			// A historical commit's changes can't be unstaged
+			enabledIf(withHelp(k.Unstage, "unstage affected files"), m.reword == ""),
+			k.Back,
+			k.Quit,
+		}
+
+	case stateTrailers:
+		switch m.trailerView {
+		case trailerAuthors:
+			s.nav = []key.Binding{k.Up, k.Down}
+			s.short = []key.Binding{withHelp(k.Enter, "add"), k.Back}
+		case trailerCustom:
+			s.short = []key.Binding{withHelp(k.Enter, "add"), k.Back}
+		default:
+			s.nav = []key.Binding{k.Up, k.Down}
+			s.short = []key.Binding{k.SignOff, k.CoAuthor, k.AddTrailer, k.DeleteTrailer, withHelp(k.Enter, "done")}
+		}
+
+	case stateSplit:
+		if m.splitEditing {
+			s.short = []key.Binding{k.Save, withHelp(k.Back, "cancel")}
+			break
+		}
+		s.nav = []key.Binding{k.Up, k.Down}
+		s.short = []key.Binding{
+			withHelp(k.Left, "move to previous commit"),
+			withHelp(k.Right, "move to next commit"),
+			k.NewGroup,
+			withHelp(k.Edit, "edit message"),
+			withHelp(k.Enter, "commit all"),
+			k.Back,
+		}
+		s.more = []key.Binding{k.Quit}
+
+	case stateCommitting:
+		s.nav = []key.Binding{withHelp(k.Up, "scroll up"), withHelp(k.Down, "scroll down")}
+		if m.committing {
+			break
+		}
+		split := m.selectedMode == modeSplit
+		retry, back := "retry", "back to review"
+		if split {
+			retry, back = "retry remaining commits", "back to split"
+		}
+		s.short = []key.Binding{
+			enabledIf(k.Restage, len(m.hookModified) > 0 && !split),
+			withHelp(k.Retry, retry),
+			withHelp(k.Edit, "edit message"),
+			withHelp(k.Back, back),
+			k.Quit,
+		}
+
+	case stateSuccess:
+		if m.pushing {
+			break
+		}
+		push := k.Push
+		if m.summary.upstream != "" {
+			push = withHelp(push, "push to "+m.summary.upstream)
+		}
+		s.short = []key.Binding{
+			enabledIf(push, m.summary.upstream != ""),
+			enabledIf(k.SetUpstream, m.summary.branch != "" && m.summary.upstream == ""),
+			enabledIf(k.CopySHA, m.summary.sha != ""),
+			anyKey("exit"),
+		}
+
+	case stateError:
+		s.short = []key.Binding{anyKey("exit")}
+	}
+
+	return s
+}
+
+// anyKey describes screens that react to every other key
+func anyKey(desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(""), key.WithHelp("any key", desc))
+}
+
+// viewHelp renders the help line of the current screen
+func (m *Model) viewHelp() string {
+	return m.help.ShortHelpView(m.screenKeys().ShortHelp())
+}
+
+// viewHelpOverlay lists every binding of the current screen
+func (m *Model) viewHelpOverlay() string {
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(1, 2).
+		Render(m.help.FullHelpView(m.screenKeys().FullHelp()))
+
+	closeKeys := m.keys.Help
+	closeKeys.SetHelp(m.keys.Help.Help().Key+"/"+m.keys.Back.Help().Key, "close")
+
+	return fmt.Sprintf(
+		"%s\n\n%s\n\n%s",
+		ui.Title("Key bindings"),
+		box,
+		m.help.ShortHelpView([]key.Binding{closeKeys}),
+	)
+}
+
+// bindListKeys makes a list move with the key map and leaves quitting and
+// help to the app
+func (m *Model) bindListKeys(l *list.Model) {
+	l.KeyMap.CursorUp = m.keys.Up
+	l.KeyMap.CursorDown = m.keys.Down
+	l.KeyMap.Quit.SetEnabled(false)
+	l.KeyMap.ForceQuit.SetEnabled(false)
+	l.KeyMap.ShowFullHelp.SetEnabled(false)
+	l.KeyMap.CloseFullHelp.SetEnabled(false)
+	l.SetShowHelp(false)
+}
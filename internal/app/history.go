@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/oconnorjohnson/add-n-commit/internal/message"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+var (
+	diffDeleteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#FF4672", Dark: "#ED567A"}).
+			Strikethrough(true)
+	diffInsertStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"})
+)
+
+// addVersion keeps a message in the history without selecting it. Repeats
+// of the latest version aren't kept twice.
+func (m *Model) addVersion(msg string) {
+	if strings.TrimSpace(msg) == "" {
+		return
+	}
+	if n := len(m.history); n > 0 && m.history[n-1] == msg {
+		return
+	}
+	m.history = append(m.history, msg)
+}
+
+// recordVersion adds a generated or edited message to the history and
+// makes it the current version
+func (m *Model) recordVersion(msg string) {
+	m.addVersion(msg)
+	if len(m.history) > 0 && m.history[len(m.history)-1] == msg {
+		m.historyPos = len(m.history) - 1
+	}
+}
+
+// stepVersion moves through the history by delta and shows that version.
+// Stepping away from an unsaved change keeps it as the newest version.
+func (m *Model) stepVersion(delta int) {
+	if current := m.textarea.Value(); len(m.history) > 0 && current != m.history[m.historyPos] {
+		m.addVersion(current)
+	}
+
+	pos := m.historyPos + delta
+	if pos < 0 || pos >= len(m.history) {
+		return
+	}
+	m.historyPos = pos
+	m.generatedMsg = m.history[pos]
+	m.textarea.SetValue(m.generatedMsg)
+	m.violations = m.validateMessage(m.generatedMsg)
+	m.saveDraft()
+}
+
+// resetHistory forgets the versions of the previous commit's message
+func (m *Model) resetHistory() {
+	m.history = nil
+	m.historyPos = 0
+	m.showDiff = false
+}
+
+// viewHistory shows which version is under review and, when toggled, what
+// changed since the version before it
+func (m *Model) viewHistory() string {
+	if len(m.history) < 2 {
+		return ""
+	}
+
+	s := "\n" + ui.Subtle(fmt.Sprintf("Version %d of %d", m.historyPos+1, len(m.history)))
+	if !m.showDiff {
+		return s
+	}
+	if m.historyPos == 0 {
+		return s + ui.Subtle(", this is the first version")
+	}
+
+	var b strings.Builder
+	for _, part := range message.DiffWords(m.history[m.historyPos-1], m.history[m.historyPos]) {
+		switch part.Op {
+		case message.DiffDelete:
+			b.WriteString(renderDiffPart(diffDeleteStyle, part.Text))
+		case message.DiffInsert:
+			b.WriteString(renderDiffPart(diffInsertStyle, part.Text))
+		default:
+			b.WriteString(part.Text)
+		}
+	}
+	return s + ui.Subtle(fmt.Sprintf(", changes since version %d:", m.historyPos)) + "\n" + b.String()
+}
+
+// renderDiffPart styles each line on its own so the styling doesn't pad
+// multi-line parts into a block
+func renderDiffPart(style lipgloss.Style, text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = style.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+var (
+	diffAddStyle    = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"})
+	diffRemoveStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#FF4672", Dark: "#ED567A"})
+	diffHunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+)
+
+// resize recomputes the layout and fits every component to it. It runs on
+// each resize and when a list is rebuilt.
+func (m *Model) resize() {
+	m.layout = ui.NewLayout(m.width, m.height)
+	main := m.layout.Main
+
+	// Leave room for the title and help, and the session log below
+	listHeight := main.Height - 8
+	if len(m.sessionLog) > 0 {
+		listHeight -= min(len(m.sessionLog), sessionLogLines) + 3
+	}
+	m.fileList.SetSize(main.Width, max(listHeight, 5))
+	m.modeList.SetSize(main.Width, min(max(main.Height-8, 5), 10))
+	m.draftList.SetSize(main.Width, max(main.Height-10, 5))
+
+	// Commit messages wrap at 72 columns, wider doesn't help
+	m.textarea.SetWidth(min(main.Width, 80))
+	m.textarea.SetHeight(min(max(main.Height-16, 6), 20))
+
+	inputWidth := min(main.Width, 80) - 4
+	m.textinput.Width = inputWidth
+	m.apiKeyInput.Width = inputWidth
+	m.trailerInput.Width = inputWidth
+
+	m.commitView.Width = main.Width - 4
+	m.commitView.Height = max(main.Height-10, 5)
+
+	m.help.Width = main.Width
+}
+
+// loadPreview loads the side pane of the current screen in the background
+// when the terminal is wide enough to show it
+func (m *Model) loadPreview() tea.Cmd {
+	if !m.layout.Split() {
+		return nil
+	}
+
+	switch m.state {
+	case stateFileSelection:
+		item, ok := m.fileList.SelectedItem().(ui.FileItem)
+		if !ok {
+			// Directories have nothing to preview
+			m.previewPath = ""
+			m.preview = ""
+			return nil
+		}
+		if item.File.Path == m.previewPath {
+			return nil
+		}
+		m.previewPath = item.File.Path
+		m.preview = ""
+		return func() tea.Msg {
+			diff, err := git.GetWorktreeDiff(item.File)
+			if err != nil {
+				diff = err.Error()
+			}
+			return previewLoadedMsg{path: item.File.Path, diff: diff}
+		}
+
+	case stateReviewing, stateEditing:
+		if m.reviewDiff != "" {
+			return nil
+		}
+		changes := m.changes
+		return func() tea.Msg {
+			diff, err := changes.Diff()
+			if err != nil {
+				diff = err.Error()
+			}
+			return reviewDiffLoadedMsg{diff: diff}
+		}
+	}
+	return nil
+}
+
+// viewDiffPane renders a diff for a side pane of height lines
+func (m *Model) viewDiffPane(title, diff string, height int) string {
+	if !m.layout.Split() {
+		return ""
+	}
+
+	width := m.layout.Side.Width - 4
+	if title == "" {
+		return ui.Subtle("Select a file to preview its changes")
+	}
+	if diff == "" {
+		return ui.Subtle(title) + "\n\n" + ui.Subtle("Loading...")
+	}
+
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"),
+			strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "index "):
+			lines[i] = ui.Subtle(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = diffAddStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = diffRemoveStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = diffHunkStyle.Render(line)
+		}
+	}
+	return ui.Clip(ui.Subtle(title)+"\n\n"+strings.Join(lines, "\n"), width, height)
+}
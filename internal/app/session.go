@@ -0,0 +1,70 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+const sessionLogLines = 5
+
+// SessionLog returns the commits made in this session, oldest first
+func (m *Model) SessionLog() []git.CommitInfo {
+	return m.sessionLog
+}
+
+// nextCommit records the commits just made and starts over with a fresh
+// file selection, keeping the chosen profile and redaction choice
+func (m *Model) nextCommit(count int) (tea.Model, tea.Cmd) {
+	commits, err := git.GetLog(count)
+	if err != nil {
+		m.errorMsg = err.Error()
+		m.state = stateError
+		return m, nil
+	}
+	for i := len(commits) - 1; i >= 0; i-- {
+		m.sessionLog = append(m.sessionLog, commits[i])
+	}
+
+	m.selectedFiles = nil
+	m.generatedMsg = ""
+	m.customPrompt = ""
+	m.violations = nil
+	m.findings = nil
+	m.trailers = nil
+	m.hunks = nil
+	m.groups = nil
+	m.splitDone = 0
+	m.resumable = nil
+	m.resetHistory()
+	m.snapshotIndex()
+	m.textarea.SetValue("")
+	m.textinput.SetValue("")
+
+	m.state = stateFileSelection
+	return m, m.loadFiles
+}
+
+// viewSessionLog lists the commits made so far in session mode
+func (m *Model) viewSessionLog() string {
+	if len(m.sessionLog) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n\n" + ui.Subtle(fmt.Sprintf("Committed this session (%d):", len(m.sessionLog))))
+
+	// Only the latest commits fit next to the file list
+	shown := m.sessionLog
+	if len(shown) > sessionLogLines {
+		b.WriteString("\n  " + ui.Subtle(fmt.Sprintf("... %d earlier", len(shown)-sessionLogLines)))
+		shown = shown[len(shown)-sessionLogLines:]
+	}
+	for _, c := range shown {
+		b.WriteString(fmt.Sprintf("\n  %s %s", ui.SuccessStyle.Render(c.Hash), c.Subject))
+	}
+	return b.String()
+}
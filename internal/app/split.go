@@ -0,0 +1,414 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/message"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+// splitGroup is one commit of a split, with indexes into Model.hunks
+type splitGroup struct {
+	message string
+	hunks   []int
+}
+
+// splitRow is a line of the split editor, a group header when hunk is -1
+type splitRow struct {
+	group int
+	hunk  int
+}
+
+const splitFormat = "Each hunk is numbered. Respond with only a JSON array of commits in the order they should be made, " +
+	`like [{"message": "commit message", "hunks": [1, 2]}]. Every hunk must be in exactly one commit.`
+
+// proposeSplit asks the model to group the staged hunks into commits
+func (m *Model) proposeSplit(prepare func(string) string) tea.Msg {
+	hunks, err := git.GetStagedHunks()
+	if err != nil {
+		return errorMsg{err: err}
+	}
+	if len(hunks) == 0 {
+		return errorMsg{err: fmt.Errorf("no staged changes to split")}
+	}
+
+	exclude, err := git.NewPathMatcher(m.config.LLMExclude)
+	if err != nil {
+		return errorMsg{err: err}
+	}
+
+	var b strings.Builder
+	for i, h := range hunks {
+		fmt.Fprintf(&b, "### Hunk %d (%s)\n", i+1, h.File)
+		if exclude.Match(h.File) {
+			summary, _ := m.changes.Summary([]string{h.File})
+			b.WriteString("Diff omitted, summary of the change:\n" + summary + "\n")
+			continue
+		}
+		b.WriteString(prepare(h.Patch()) + "\n")
+	}
+
+	response, err := m.openaiClient.GenerateCommitMessage(
+		m.systemPrompt(m.config.SystemPromptSplit+" "+splitFormat),
+		b.String(),
+	)
+	if err != nil {
+		return errorMsg{err: err}
+	}
+
+	groups, err := parseSplit(response, len(hunks))
+	if err != nil {
+		return errorMsg{err: err}
+	}
+
+	for i := range groups {
+		if groups[i].message, err = m.applyTickets(groups[i].message); err != nil {
+			return errorMsg{err: err}
+		}
+	}
+
+	return splitProposedMsg{hunks: hunks, groups: groups}
+}
+
+// parseSplit reads the model's grouping. Hunks it left out or repeated are
+// collected in a final group so nothing is lost.
+func parseSplit(response string, hunkCount int) ([]splitGroup, error) {
+	start := strings.Index(response, "[")
+	end := strings.LastIndex(response, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("the model did not return a list of commits:\n%s", response)
+	}
+
+	var proposed []struct {
+		Message string `json:"message"`
+		Hunks   []int  `json:"hunks"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &proposed); err != nil {
+		return nil, fmt.Errorf("failed to read the proposed commits: %w", err)
+	}
+
+	assigned := make([]bool, hunkCount)
+	var groups []splitGroup
+	for _, p := range proposed {
+		group := splitGroup{message: strings.TrimSpace(p.Message)}
+		for _, n := range p.Hunks {
+			if n >= 1 && n <= hunkCount && !assigned[n-1] {
+				assigned[n-1] = true
+				group.hunks = append(group.hunks, n-1)
+			}
+		}
+		if len(group.hunks) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	var rest []int
+	for i, ok := range assigned {
+		if !ok {
+			rest = append(rest, i)
+		}
+	}
+	if len(rest) > 0 {
+		groups = append(groups, splitGroup{message: "Other changes", hunks: rest})
+	}
+
+	return groups, nil
+}
+
+func (m *Model) splitRows() []splitRow {
+	var rows []splitRow
+	for g, group := range m.groups {
+		rows = append(rows, splitRow{group: g, hunk: -1})
+		for h := range group.hunks {
+			rows = append(rows, splitRow{group: g, hunk: h})
+		}
+	}
+	return rows
+}
+
+func (m *Model) updateSplit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.splitEditing {
+		return m.updateSplitMessage(msg)
+	}
+
+	rows := m.splitRows()
+	row := rows[m.splitCursor]
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.splitCursor > 0 {
+			m.splitCursor--
+		}
+
+	case key.Matches(msg, m.keys.Down):
+		if m.splitCursor < len(rows)-1 {
+			m.splitCursor++
+		}
+
+	case key.Matches(msg, m.keys.Left):
+		if row.hunk >= 0 && row.group > 0 {
+			m.moveHunk(row, row.group-1)
+		}
+
+	case key.Matches(msg, m.keys.Right):
+		if row.hunk >= 0 && row.group < len(m.groups)-1 {
+			m.moveHunk(row, row.group+1)
+		}
+
+	case key.Matches(msg, m.keys.NewGroup):
+		if row.hunk >= 0 && len(m.groups[row.group].hunks) > 1 {
+			m.groups = append(m.groups, splitGroup{})
+			m.moveHunk(row, len(m.groups)-1)
+			return m.editSplitMessage()
+		}
+
+	case key.Matches(msg, m.keys.Edit):
+		return m.editSplitMessage()
+
+	case key.Matches(msg, m.keys.Enter):
+		return m.commitSplitIfValid()
+
+	case key.Matches(msg, m.keys.Back):
+		m.splitErr = ""
+		m.state = stateModeSelection
+
+	case key.Matches(msg, m.keys.Quit):
+		m.cleanup()
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// moveHunk moves the hunk on a row to another group, dropping the group
+// it leaves if that becomes empty, and keeps the cursor on the hunk
+func (m *Model) moveHunk(row splitRow, to int) {
+	from := &m.groups[row.group]
+	idx := from.hunks[row.hunk]
+	from.hunks = append(from.hunks[:row.hunk], from.hunks[row.hunk+1:]...)
+
+	m.groups[to].hunks = append(m.groups[to].hunks, idx)
+	sort.Ints(m.groups[to].hunks)
+
+	if len(from.hunks) == 0 {
+		m.groups = append(m.groups[:row.group], m.groups[row.group+1:]...)
+	}
+
+	for i, r := range m.splitRows() {
+		if r.hunk >= 0 && m.groups[r.group].hunks[r.hunk] == idx {
+			m.splitCursor = i
+			break
+		}
+	}
+	m.splitErr = ""
+}
+
+func (m *Model) editSplitMessage() (tea.Model, tea.Cmd) {
+	group := m.splitRows()[m.splitCursor].group
+	m.splitEditing = true
+	m.textarea.SetValue(m.groups[group].message)
+	m.textarea.Focus()
+	return m, textarea.Blink
+}
+
+func (m *Model) updateSplitMessage(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Save):
+		group := m.splitRows()[m.splitCursor].group
+		m.groups[group].message = strings.TrimSpace(m.textarea.Value())
+		m.splitEditing = false
+		m.splitErr = ""
+		m.textarea.Blur()
+		return m, nil
+
+	case key.Matches(msg, m.keys.Back):
+		m.splitEditing = false
+		m.textarea.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textarea, cmd = m.textarea.Update(msg)
+	return m, cmd
+}
+
+// commitSplitIfValid checks every group's message before creating any of
+// the commits
+func (m *Model) commitSplitIfValid() (tea.Model, tea.Cmd) {
+	for i, group := range m.groups {
+		if group.message == "" {
+			m.splitErr = fmt.Sprintf("Commit %d has no message", i+1)
+			return m, nil
+		}
+		for _, v := range m.validateMessage(group.message) {
+			if v.Severity == message.SeverityError {
+				m.splitErr = fmt.Sprintf("Commit %d: %s (%s)", i+1, v.Message, v.Rule)
+				return m, nil
+			}
+		}
+	}
+
+	return m, m.commitSplit()
+}
+
+// commitSplit creates the commits one after another from the hunks of each
+// group, streaming the output of git and its hooks. If one fails, the
+// hunks that weren't committed yet are staged again and the commits made
+// so far are kept, so a retry continues with the failed group.
+func (m *Model) commitSplit() tea.Cmd {
+	hunks := m.hunks
+	groups := append([]splitGroup(nil), m.groups...)
+	done := m.splitDone
+	opts := m.commitOptions()
+	ch := m.startCommitLog()
+
+	go func() {
+		if err := git.ResetIndex(); err != nil {
+			ch <- errorMsg{err: err}
+			return
+		}
+
+		for i, group := range groups {
+			ch <- commitOutputMsg{line: fmt.Sprintf("[%d/%d] %s", done+i+1, done+len(groups), message.Subject(group.message))}
+
+			msg, err := m.finalMessage(group.message)
+			if err == nil {
+				err = git.ApplyToIndex(git.BuildPatch(pickHunks(hunks, group.hunks)))
+			}
+			if err == nil {
+				err = git.CommitStreaming(msg, opts, func(line string, replace bool) {
+					ch <- commitOutputMsg{line: line, replace: replace}
+				})
+			}
+			if err == nil {
+				continue
+			}
+
+			var rest []int
+			for _, g := range groups[i:] {
+				rest = append(rest, g.hunks...)
+			}
+			sort.Ints(rest)
+
+			restoreErr := git.ResetIndex()
+			if restoreErr == nil {
+				restoreErr = git.ApplyToIndex(git.BuildPatch(pickHunks(hunks, rest)))
+			}
+			if restoreErr != nil {
+				ch <- errorMsg{err: fmt.Errorf("created %d of %d commits, then failed to stage the rest again (%v): %w",
+					done+i, done+len(groups), restoreErr, err)}
+				return
+			}
+			ch <- commitFailedMsg{err: err, committed: i}
+			return
+		}
+
+		total := done + len(groups)
+		ch <- splitCommittedMsg{count: total, summary: summarizeCommits(total)}
+	}()
+
+	return waitForMsg(ch)
+}
+
+func pickHunks(hunks []git.Hunk, indexes []int) []git.Hunk {
+	sorted := append([]int(nil), indexes...)
+	sort.Ints(sorted)
+
+	picked := make([]git.Hunk, 0, len(sorted))
+	for _, idx := range sorted {
+		picked = append(picked, hunks[idx])
+	}
+	return picked
+}
+
+func (m *Model) viewSplit() string {
+	if m.splitEditing {
+		group := m.splitRows()[m.splitCursor].group
+		return fmt.Sprintf(
+			"%s\n\n%s\n\n%s",
+			ui.Title(fmt.Sprintf("Edit message of commit %d", group+1)),
+			m.textarea.View(),
+			m.viewHelp(),
+		)
+	}
+
+	rows := m.splitRows()
+
+	// Keep the cursor in view on small terminals
+	visible := m.height - 10
+	if visible < 5 {
+		visible = 5
+	}
+	first := 0
+	if m.splitCursor >= visible {
+		first = m.splitCursor - visible + 1
+	}
+	last := first + visible
+	if last > len(rows) {
+		last = len(rows)
+	}
+
+	var b strings.Builder
+	for i := first; i < last; i++ {
+		row := rows[i]
+		group := m.groups[row.group]
+
+		var line string
+		if row.hunk < 0 {
+			line = fmt.Sprintf("%d. %s%s", row.group+1, message.Subject(group.message), m.splitMarker(group))
+		} else {
+			line = "     " + hunkLabel(m.hunks[group.hunks[row.hunk]])
+		}
+
+		if i == m.splitCursor {
+			b.WriteString(ui.SelectedStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString("  " + ui.NormalStyle.Render(line) + "\n")
+		}
+	}
+
+	errLine := ""
+	if m.splitErr != "" {
+		errLine = "\n\n" + ui.ErrorStyle.Render(m.splitErr)
+	}
+
+	return fmt.Sprintf(
+		"%s\n\n%s%s\n\n%s\n%s",
+		ui.Title(fmt.Sprintf("Split into %d commits", len(m.groups))),
+		strings.TrimRight(b.String(), "\n"),
+		errLine,
+		m.viewCommitOptions(),
+		m.viewHelp(),
+	)
+}
+
+// splitMarker flags groups whose message has lint problems
+func (m *Model) splitMarker(group splitGroup) string {
+	violations := m.validateMessage(group.message)
+	switch {
+	case message.HasErrors(violations):
+		return " " + ui.ErrorStyle.Render("✗")
+	case len(violations) > 0:
+		return " " + ui.WarningStyle.Render("!")
+	}
+	return ""
+}
+
+// hunkLabel describes a hunk by its file and hunk header
+func hunkLabel(h git.Hunk) string {
+	if h.Body == "" {
+		return h.File + " (whole file)"
+	}
+	header := h.Body
+	if idx := strings.IndexByte(header, '\n'); idx >= 0 {
+		header = header[:idx]
+	}
+	return h.File + " " + header
+}
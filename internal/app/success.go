@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+// pushOutputLines is how much push output the success screen keeps
+const pushOutputLines = 8
+
+// commitSummary describes the commits just made
+type commitSummary struct {
+	sha      string // Full hash of HEAD
+	short    string
+	branch   string // Empty when HEAD is detached
+	upstream string
+	stat     string // --shortstat of the commits, empty when unknown
+}
+
+// summarizeCommits describes the last count commits on HEAD. With a count
+// of zero only HEAD itself is described, without stats. Everything is
+// best effort, the commits are made either way.
+func summarizeCommits(count int) commitSummary {
+	var s commitSummary
+	s.sha, _ = git.ResolveCommit("HEAD")
+	if log, err := git.GetLog(1); err == nil && len(log) > 0 {
+		s.short = log[0].Hash
+	}
+	s.branch, _ = git.GetCurrentBranch()
+	s.upstream = git.GetUpstream()
+
+	if count > 0 {
+		if base, err := git.ParentOf(fmt.Sprintf("HEAD~%d", count-1)); err == nil {
+			s.stat, _ = git.GetShortStat(base, "HEAD")
+		}
+	}
+	return s
+}
+
+func (m *Model) updateSuccess(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.pushing {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Push):
+		if m.summary.upstream != "" {
+			return m, m.startPush(false, "")
+		}
+		return m, nil
+
+	case key.Matches(msg, m.keys.SetUpstream):
+		if m.summary.branch == "" || m.summary.upstream != "" {
+			return m, nil
+		}
+		remote, err := git.GetDefaultRemote(m.summary.branch)
+		if err != nil {
+			m.pushStatus = ""
+			m.pushErr = err.Error()
+			return m, nil
+		}
+		return m, m.startPush(true, remote)
+
+	case key.Matches(msg, m.keys.CopySHA):
+		if m.summary.sha == "" {
+			return m, nil
+		}
+		// Fall back to OSC 52 when there is no system clipboard, which
+		// also works over SSH in most terminals
+		if err := clipboard.WriteAll(m.summary.sha); err != nil {
+			termenv.Copy(m.summary.sha)
+		}
+		m.pushErr = ""
+		m.pushStatus = fmt.Sprintf("Copied %s to the clipboard", m.summary.short)
+		return m, nil
+	}
+
+	return m, tea.Quit
+}
+
+// startPush runs git push in the background and streams its output to the
+// success screen through pushOutputMsg
+func (m *Model) startPush(setUpstream bool, remote string) tea.Cmd {
+	ch := make(chan tea.Msg)
+	branch := m.summary.branch
+
+	m.pushing = true
+	m.pushOutput = nil
+	m.pushStatus = ""
+	m.pushErr = ""
+	m.pushCh = ch
+
+	go func() {
+		err := git.Push(setUpstream, remote, branch, func(line string, replace bool) {
+			ch <- pushOutputMsg{line: line, replace: replace}
+		})
+		ch <- pushDoneMsg{err: err}
+	}()
+
+	return waitForMsg(ch)
+}
+
+// waitForMsg waits for the next message from a background command
+func waitForMsg(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+func (m *Model) addPushOutput(msg pushOutputMsg) {
+	if msg.replace && len(m.pushOutput) > 0 {
+		m.pushOutput[len(m.pushOutput)-1] = msg.line
+	} else {
+		m.pushOutput = append(m.pushOutput, msg.line)
+	}
+	if len(m.pushOutput) > pushOutputLines {
+		m.pushOutput = m.pushOutput[len(m.pushOutput)-pushOutputLines:]
+	}
+}
+
+func (m *Model) viewSuccess() string {
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("42")).
+		Bold(true)
+
+	var b strings.Builder
+	b.WriteString(style.Render(m.successMsg))
+
+	if m.summary.short != "" {
+		where := "detached HEAD"
+		if m.summary.branch != "" {
+			where = m.summary.branch
+		}
+		b.WriteString(fmt.Sprintf("\n\n%s on %s", ui.SuccessStyle.Render(m.summary.short), where))
+		if m.summary.stat != "" {
+			b.WriteString("\n" + ui.Subtle(m.summary.stat))
+		}
+	}
+
+	if len(m.pushOutput) > 0 {
+		b.WriteString("\n\n" + ui.Subtle(strings.Join(m.pushOutput, "\n")))
+	}
+
+	switch {
+	case m.pushing:
+		b.WriteString("\n\n" + ui.Subtle("Pushing..."))
+	case m.pushErr != "":
+		b.WriteString("\n\n" + ui.ErrorStyle.Render(m.pushErr))
+	case m.pushStatus != "":
+		b.WriteString("\n\n" + ui.SuccessStyle.Render("✓ "+m.pushStatus))
+	}
+
+	if !m.pushing {
+		b.WriteString("\n\n" + m.viewHelp())
+	}
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/oconnorjohnson/add-n-commit/internal/message"
+)
+
+// templatePrompt asks the model to fill in the repository's commit
+// template, if there is one
+func (m *Model) templatePrompt() string {
+	if strings.TrimSpace(m.template) == "" {
+		return ""
+	}
+	return "\n\nThis repository uses the commit message template below. Follow its structure: keep its " +
+		"section headings and fill in every section from the changes, leaving out sections that don't apply. " +
+		"Lines starting with " + m.commentChar + " are guidance for writing the message, follow them " +
+		"but don't include them.\n\n" + m.template
+}
+
+// stripTemplateComments removes the template's guidance from a message.
+// Other lines starting with the comment character are left alone, as git
+// commit -m does, since they may be part of the message.
+func (m *Model) stripTemplateComments(msg string) string {
+	if m.template == "" {
+		return msg
+	}
+	return message.StripTemplateComments(msg, m.template, m.commentChar)
+}
+
+// finalMessage is the message as committed, with the template's comments
+// removed and the pending trailers applied
+func (m *Model) finalMessage(msg string) (string, error) {
+	return m.withTrailers(m.stripTemplateComments(msg))
+}
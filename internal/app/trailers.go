@@ -0,0 +1,255 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+// Views of the trailer editor
+type trailerView int
+
+const (
+	trailerList trailerView = iota
+	trailerAuthors
+	trailerCustom
+)
+
+const signOffKey = "Signed-off-by"
+
+var trailerPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: \S.*$`)
+
+func (m *Model) openTrailerEditor() (tea.Model, tea.Cmd) {
+	m.state = stateTrailers
+	m.trailerView = trailerList
+	m.trailerCursor = 0
+	return m, nil
+}
+
+func (m *Model) updateTrailers(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.trailerView {
+	case trailerAuthors:
+		return m.updateTrailerAuthors(msg)
+	case trailerCustom:
+		return m.updateTrailerInput(msg)
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.trailerCursor > 0 {
+			m.trailerCursor--
+		}
+
+	case key.Matches(msg, m.keys.Down):
+		if m.trailerCursor < len(m.trailers)-1 {
+			m.trailerCursor++
+		}
+
+	case key.Matches(msg, m.keys.SignOff):
+		m.toggleSignOff()
+
+	case key.Matches(msg, m.keys.CoAuthor):
+		authors, err := git.GetAuthors()
+		if err != nil || len(authors) == 0 {
+			m.trailerErr = "No authors found in the history"
+			return m, nil
+		}
+		m.authors = authors
+		m.authorCursor = 0
+		m.trailerView = trailerAuthors
+
+	case key.Matches(msg, m.keys.AddTrailer):
+		m.trailerInput.SetValue("")
+		m.trailerInput.Focus()
+		m.trailerView = trailerCustom
+		return m, textinput.Blink
+
+	case key.Matches(msg, m.keys.DeleteTrailer):
+		if len(m.trailers) > 0 {
+			m.trailers = append(m.trailers[:m.trailerCursor], m.trailers[m.trailerCursor+1:]...)
+			if m.trailerCursor >= len(m.trailers) && m.trailerCursor > 0 {
+				m.trailerCursor--
+			}
+		}
+
+	case key.Matches(msg, m.keys.Enter, m.keys.Back):
+		m.trailerErr = ""
+		m.state = stateReviewing
+	}
+
+	return m, nil
+}
+
+func (m *Model) updateTrailerAuthors(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.authorCursor > 0 {
+			m.authorCursor--
+		}
+
+	case key.Matches(msg, m.keys.Down):
+		if m.authorCursor < len(m.authors)-1 {
+			m.authorCursor++
+		}
+
+	case key.Matches(msg, m.keys.Enter):
+		m.addTrailer("Co-authored-by: " + m.authors[m.authorCursor])
+		m.trailerView = trailerList
+
+	case key.Matches(msg, m.keys.Back):
+		m.trailerView = trailerList
+	}
+
+	return m, nil
+}
+
+func (m *Model) updateTrailerInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Enter):
+		trailer := strings.TrimSpace(m.trailerInput.Value())
+		if !trailerPattern.MatchString(trailer) {
+			m.trailerErr = "Trailers look like 'Key: value'"
+			return m, nil
+		}
+		m.addTrailer(trailer)
+		m.trailerInput.Blur()
+		m.trailerView = trailerList
+		return m, nil
+
+	case key.Matches(msg, m.keys.Back):
+		m.trailerInput.Blur()
+		m.trailerView = trailerList
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.trailerInput, cmd = m.trailerInput.Update(msg)
+	return m, cmd
+}
+
+// toggleSignOff adds or removes the DCO sign-off for the configured user
+func (m *Model) toggleSignOff() {
+	for i, trailer := range m.trailers {
+		if strings.HasPrefix(trailer, signOffKey+": ") {
+			m.trailers = append(m.trailers[:i], m.trailers[i+1:]...)
+			m.trailerCursor = 0
+			return
+		}
+	}
+
+	identity, err := git.GetIdentity()
+	if err != nil {
+		m.trailerErr = err.Error()
+		return
+	}
+	m.addTrailer(signOffKey + ": " + identity)
+}
+
+// addTrailer adds a trailer unless an identical one is already pending
+func (m *Model) addTrailer(trailer string) {
+	m.trailerErr = ""
+	for _, existing := range m.trailers {
+		if strings.EqualFold(existing, trailer) {
+			return
+		}
+	}
+	m.trailers = append(m.trailers, trailer)
+	m.trailerCursor = len(m.trailers) - 1
+}
+
+// withTrailers applies the pending trailers to a message the way
+// git interpret-trailers does, skipping ones the message already has
+func (m *Model) withTrailers(msg string) (string, error) {
+	return git.InterpretTrailers(msg, m.trailers)
+}
+
+func (m *Model) viewTrailers() string {
+	var content string
+
+	switch m.trailerView {
+	case trailerAuthors:
+		// Keep the cursor in view when there are more authors than rows
+		visible := m.height - 8
+		if visible < 5 {
+			visible = 5
+		}
+		first := 0
+		if m.authorCursor >= visible {
+			first = m.authorCursor - visible + 1
+		}
+		last := first + visible
+		if last > len(m.authors) {
+			last = len(m.authors)
+		}
+
+		var b strings.Builder
+		for i := first; i < last; i++ {
+			author := m.authors[i]
+			if i == m.authorCursor {
+				b.WriteString(ui.SelectedStyle.Render("> "+author) + "\n")
+			} else {
+				b.WriteString("  " + ui.NormalStyle.Render(author) + "\n")
+			}
+		}
+		if len(m.authors) > visible {
+			b.WriteString(ui.Subtle(fmt.Sprintf("  %d of %d", m.authorCursor+1, len(m.authors))) + "\n")
+		}
+		return fmt.Sprintf(
+			"%s\n\n%s\n%s",
+			ui.Title("Add co-author"),
+			b.String(),
+			m.viewHelp(),
+		)
+
+	case trailerCustom:
+		return fmt.Sprintf(
+			"%s\n\n%s\n\n%s",
+			ui.Title("Add trailer"),
+			m.trailerInput.View(),
+			m.viewHelp()+m.viewTrailerError(),
+		)
+	}
+
+	if len(m.trailers) == 0 {
+		content = ui.Subtle("No trailers")
+	} else {
+		var b strings.Builder
+		for i, trailer := range m.trailers {
+			if i == m.trailerCursor {
+				b.WriteString(ui.SelectedStyle.Render("> "+trailer) + "\n")
+			} else {
+				b.WriteString("  " + ui.NormalStyle.Render(trailer) + "\n")
+			}
+		}
+		content = strings.TrimRight(b.String(), "\n")
+	}
+
+	return fmt.Sprintf(
+		"%s\n\n%s%s\n\n%s",
+		ui.Title("Trailers"),
+		content,
+		m.viewTrailerError(),
+		m.viewHelp(),
+	)
+}
+
+func (m *Model) viewTrailerError() string {
+	if m.trailerErr == "" {
+		return ""
+	}
+	return "\n\n" + ui.ErrorStyle.Render(m.trailerErr)
+}
+
+// viewPendingTrailers lists the trailers that will be added on commit
+func (m *Model) viewPendingTrailers() string {
+	if len(m.trailers) == 0 {
+		return ""
+	}
+	return "\n\n" + ui.Subtle("Trailers added on commit:\n  "+strings.Join(m.trailers, "\n  "))
+}
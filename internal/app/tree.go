@@ -0,0 +1,81 @@
+package app
+
+import (
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
+)
+
+// rowKey identifies a file or directory row across list rebuilds
+func rowKey(item list.Item) string {
+	switch i := item.(type) {
+	case ui.FileItem:
+		return i.File.Path
+	case ui.DirItem:
+		return i.Path + "/"
+	}
+	return ""
+}
+
+// refreshFileList shows the file tree's current rows, keeping the cursor
+// on the same row when it is still visible
+func (m *Model) refreshFileList() {
+	if m.fileTree == nil {
+		return
+	}
+	current := rowKey(m.fileList.SelectedItem())
+	items := m.fileTree.Items()
+	m.fileList.SetItems(items)
+
+	for i, item := range items {
+		if rowKey(item) == current {
+			m.fileList.Select(i)
+			return
+		}
+	}
+	if m.fileList.Index() >= len(items) {
+		m.fileList.Select(len(items) - 1)
+	}
+}
+
+// collapseOrParent collapses the directory under the cursor, or moves to
+// the directory containing the row
+func (m *Model) collapseOrParent() {
+	if m.fileTree == nil || m.fileTree.Flat() {
+		return
+	}
+
+	depth := 0
+	switch i := m.fileList.SelectedItem().(type) {
+	case ui.DirItem:
+		if !i.Collapsed {
+			m.fileTree.SetCollapsed(i.Path, true)
+			m.refreshFileList()
+			return
+		}
+		depth = i.Depth
+	case ui.FileItem:
+		depth = i.Depth
+	}
+	if depth == 0 {
+		return
+	}
+
+	items := m.fileList.Items()
+	for idx := m.fileList.Index() - 1; idx >= 0; idx-- {
+		if dir, ok := items[idx].(ui.DirItem); ok && dir.Depth == depth-1 {
+			m.fileList.Select(idx)
+			return
+		}
+	}
+}
+
+// expand expands the directory under the cursor
+func (m *Model) expand() {
+	if m.fileTree == nil {
+		return
+	}
+	if dir, ok := m.fileList.SelectedItem().(ui.DirItem); ok && dir.Collapsed {
+		m.fileTree.SetCollapsed(dir.Path, false)
+		m.refreshFileList()
+	}
+}
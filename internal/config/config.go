@@ -2,73 +2,168 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/oconnorjohnson/add-n-commit/internal/message"
+	"github.com/oconnorjohnson/add-n-commit/internal/scan"
+)
+
+// Commit signing modes
+const (
+	SignAuto   = "auto"   // Sign when git's commit.gpgSign is set
+	SignAlways = "always" // Always sign
+	SignNever  = "never"  // Never sign, even if commit.gpgSign is set
 )
 
 // Config holds the application configuration
 type Config struct {
+	Provider         string `json:"provider"` // "openai" or "ollama"
+	BaseURL          string `json:"base_url,omitempty"`
 	OpenAIKey        string `json:"openai_key"`
+	KeyBackend       string `json:"key_backend,omitempty"`     // "config", "keyring" or "file"
+	APIKeyCommand    string `json:"api_key_command,omitempty"` // e.g. "pass show openai"
 	Model            string `json:"model"`
-	DefaultMode      string `json:"default_mode"`      // "all", "by-file", "interactive"
+	DefaultMode      string `json:"default_mode"`      // "all", "by-file", "conventional", "interactive"
 	AutoStageAll     bool   `json:"auto_stage_all"`    // Whether to auto-stage all files
 	Temperature      float32 `json:"temperature"`
 	SystemPromptAll  string `json:"system_prompt_all"`
 	SystemPromptFile string `json:"system_prompt_file"`
+
+	// Conventional Commits mode
+	SystemPromptConventional string            `json:"system_prompt_conventional"`
+	ConventionalTypes        []string          `json:"conventional_types"`
+	ConventionalScopes       map[string]string `json:"conventional_scopes"` // path prefix -> scope
+	ConventionalStrict       bool              `json:"conventional_strict"` // Block commits that fail validation
+
+	// Commit message linter
+	Lint message.Rules `json:"lint"`
+
+	// Secret scanning before diffs are sent to the provider
+	SecretScan scan.Config `json:"secret_scan"`
+
+	// Split mode, which groups staged hunks into several commits
+	SystemPromptSplit string `json:"system_prompt_split"`
+
+	// Gitignore-style patterns for files whose diffs are summarized
+	// instead of sent to the provider. They are still committed.
+	LLMExclude []string `json:"llm_exclude"`
+
+	// Recent commit messages used as style examples in the prompt
+	StyleExamples       int  `json:"style_examples"`         // Number of messages, 0 disables
+	StyleExamplesByPath bool `json:"style_examples_by_path"` // Only commits touching the staged files
+
+	// Ticket references extracted from the branch name
+	TicketPattern   string `json:"ticket_pattern"`   // Regex, empty (the default) disables
+	TicketPlacement string `json:"ticket_placement"` // "prefix", "trailer" or "none"
+	TicketTrailer   string `json:"ticket_trailer"`   // Trailer key for the "trailer" placement
+
+	// Group changed files by directory in file selection
+	FileTree bool `json:"file_tree"`
+
+	// Key binding overrides by action, e.g. "regenerate": ["g"]. An empty
+	// list disables the action.
+	Keys map[string][]string `json:"keys,omitempty"`
+
+	// Number of message drafts kept in .git/anc/drafts, 0 disables drafts
+	DraftHistory int `json:"draft_history"`
+
+	// Commit signing. The format (gpg.format) and default key
+	// (user.signingKey) come from git config.
+	Sign       string `json:"sign"`                  // "auto", "always" or "never"
+	SigningKey string `json:"signing_key,omitempty"` // Overrides user.signingKey
+
+	// Named profiles, selected with --profile or "profile"
+	Profiles      map[string]*Profile `json:"profiles,omitempty"`
+	ActiveProfile string              `json:"profile,omitempty"`
+
+	user        *Config // User config underneath project overrides
+	projectFile string  // Project config applied on top, if any
+	profileBase *Config // Config the active profile was applied to
 }
 
 // Default returns the default configuration
 func Default() *Config {
 	return &Config{
+		Provider:         ProviderOpenAI,
 		Model:            "o4-mini",
 		DefaultMode:      "interactive",
 		AutoStageAll:     false,
 		Temperature:      1.0,
 		SystemPromptAll:  "You are a helpful AI that writes clear and concise Git commit messages based on diffs.",
 		SystemPromptFile: "You are a helpful AI that writes concise Git commit messages per file.",
+		SystemPromptConventional: "You are a helpful AI that writes Git commit messages following the Conventional Commits specification. " +
+			"The subject line must be 'type(scope): description' in the imperative mood, without a trailing period. " +
+			"Mark breaking changes with '!' after the type or scope and explain them in a 'BREAKING CHANGE:' footer.",
+		SystemPromptSplit: "You are a helpful AI that splits unrelated staged changes into a series of small, coherent Git commits. " +
+			"Group hunks that belong to the same logical change and write a clear commit message for each group.",
+		ConventionalTypes:  append([]string(nil), message.DefaultTypes...),
+		ConventionalStrict: true,
+		Lint:               message.DefaultRules(),
+		SecretScan:         scan.DefaultConfig(),
+		LLMExclude: []string{
+			"go.sum", "package-lock.json", "yarn.lock", "pnpm-lock.yaml",
+			"Cargo.lock", "poetry.lock", "Gemfile.lock", "composer.lock",
+			"*.min.js", "*.min.css", "*.map", "*.pb.go",
+			"vendor/", "node_modules/",
+		},
+		StyleExamples: 5,
+		TicketPlacement: message.TicketTrailer,
+		TicketTrailer:   "Refs",
+		Sign:            SignAuto,
+		DraftHistory:    10,
+		FileTree:        true,
 	}
 }
 
-// Load loads configuration from file
-func Load() (*Config, error) {
+// Dir returns the directory holding the user configuration
+func Dir() (string, error) {
 	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "anc"), nil
+}
+
+// Load loads configuration from file. The OPENAI_API_KEY environment
+// variable is resolved by APIKey rather than copied into the config, so it
+// never ends up saved to disk.
+func Load() (*Config, error) {
+	configDir, err := Dir()
 	if err != nil {
 		return nil, err
 	}
 
-	configPath := filepath.Join(homeDir, ".config", "anc", "config.json")
+	configPath := filepath.Join(configDir, "config.json")
 	
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		// Try environment variable for API key
-		cfg := Default()
-		if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
-			cfg.OpenAIKey = apiKey
-		}
-		return cfg, err
+		return Default(), err
 	}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	// Start from the defaults so settings missing from older files keep
+	// sensible values
+	cfg := Default()
+	if err := json.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
 
-	// Override with environment variable if set
-	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
-		cfg.OpenAIKey = apiKey
-	}
-
-	return &cfg, nil
+	return cfg, nil
 }
 
-// Save saves the configuration to file
+// Save saves the configuration to file. Configurations with project
+// overrides can't be saved; save User() instead.
 func (c *Config) Save() error {
-	homeDir, err := os.UserHomeDir()
+	if c.user != nil {
+		return fmt.Errorf("cannot save configuration with project overrides from %s", c.projectFile)
+	}
+
+	configDir, err := Dir()
 	if err != nil {
 		return err
 	}
 
-	configDir := filepath.Join(homeDir, ".config", "anc")
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return err
 	}
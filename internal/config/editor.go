@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
 )
 
 // ConfigEditor is a TUI for editing configuration
@@ -16,56 +19,85 @@ type ConfigEditor struct {
 	focusIndex  int
 	saved       bool
 	err         error
+	profile     string // Profile being edited, empty for the base settings
+	keys        ui.KeyMap
+	help        help.Model
 }
 
 // NewConfigEditor creates a new configuration editor
 func NewConfigEditor(cfg *Config) *ConfigEditor {
-	inputs := make([]textinput.Model, 6)
+	inputs := make([]textinput.Model, 8)
 	
 	// API Key
 	inputs[0] = textinput.New()
 	inputs[0].Placeholder = "sk-..."
-	inputs[0].SetValue(cfg.OpenAIKey)
 	inputs[0].EchoMode = textinput.EchoPassword
 	inputs[0].CharLimit = 100
 	
 	// Model
 	inputs[1] = textinput.New()
 	inputs[1].Placeholder = "o4-mini"
-	inputs[1].SetValue(cfg.Model)
 	inputs[1].CharLimit = 50
 	
 	// Default Mode
 	inputs[2] = textinput.New()
-	inputs[2].Placeholder = "interactive/all/by-file"
-	inputs[2].SetValue(cfg.DefaultMode)
+	inputs[2].Placeholder = "interactive/all/by-file/conventional"
 	inputs[2].CharLimit = 20
 	
 	// Temperature
 	inputs[3] = textinput.New()
 	inputs[3].Placeholder = "1.0"
-	inputs[3].SetValue(fmt.Sprintf("%.1f", cfg.Temperature))
 	inputs[3].CharLimit = 5
 	
 	// System Prompt All
 	inputs[4] = textinput.New()
 	inputs[4].Placeholder = "System prompt for all-in-one mode..."
-	inputs[4].SetValue(cfg.SystemPromptAll)
 	inputs[4].CharLimit = 500
 	
 	// System Prompt File
 	inputs[5] = textinput.New()
 	inputs[5].Placeholder = "System prompt for file-by-file mode..."
-	inputs[5].SetValue(cfg.SystemPromptFile)
 	inputs[5].CharLimit = 500
 	
+	// Provider
+	inputs[6] = textinput.New()
+	inputs[6].Placeholder = "openai/ollama"
+	inputs[6].CharLimit = 20
+	
+	// Base URL
+	inputs[7] = textinput.New()
+	inputs[7].Placeholder = "Leave empty for the provider default"
+	inputs[7].CharLimit = 200
+	
 	// Focus on first input
 	inputs[0].Focus()
 	
-	return &ConfigEditor{
+	e := &ConfigEditor{
 		config: cfg,
 		inputs: inputs,
+		help:   help.New(),
 	}
+	// Unknown actions are reported by main before the editor starts
+	e.keys, _ = ui.NewKeyMap(cfg.Keys)
+	e.loadValues()
+	
+	return e
+}
+
+// SetProfile switches the editor to the named profile, creating it if it
+// doesn't exist yet. An empty name edits the base settings.
+func (e *ConfigEditor) SetProfile(name string) {
+	if name != "" {
+		if e.config.Profiles == nil {
+			e.config.Profiles = make(map[string]*Profile)
+		}
+		if e.config.Profiles[name] == nil {
+			e.config.Profiles[name] = &Profile{}
+		}
+	}
+	
+	e.profile = name
+	e.loadValues()
 }
 
 func (e *ConfigEditor) Init() tea.Cmd {
@@ -75,11 +107,16 @@ func (e *ConfigEditor) Init() tea.Cmd {
 func (e *ConfigEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "esc":
+		// Ctrl+C always quits, like in the app
+		if msg.String() == "ctrl+c" {
+			return e, tea.Quit
+		}
+		
+		switch {
+		case key.Matches(msg, e.keys.Back):
 			return e, tea.Quit
 			
-		case "tab", "down":
+		case key.Matches(msg, e.keys.NextField):
 			e.focusIndex++
 			if e.focusIndex >= len(e.inputs) {
 				e.focusIndex = 0
@@ -87,7 +124,7 @@ func (e *ConfigEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			e.updateFocus()
 			return e, textinput.Blink
 			
-		case "shift+tab", "up":
+		case key.Matches(msg, e.keys.PrevField):
 			e.focusIndex--
 			if e.focusIndex < 0 {
 				e.focusIndex = len(e.inputs) - 1
@@ -95,8 +132,23 @@ func (e *ConfigEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			e.updateFocus()
 			return e, textinput.Blink
 			
-		case "ctrl+s", "enter":
-			if e.focusIndex == len(e.inputs)-1 || msg.String() == "ctrl+s" {
+		case key.Matches(msg, e.keys.NextProfile):
+			// Keep the edits to the current profile and move to the next one
+			if err := e.storeValues(); err != nil {
+				e.err = err
+				return e, nil
+			}
+			names := append([]string{""}, e.config.ProfileNames()...)
+			for i, name := range names {
+				if name == e.profile {
+					e.SetProfile(names[(i+1)%len(names)])
+					break
+				}
+			}
+			return e, textinput.Blink
+			
+		case key.Matches(msg, e.keys.Save, e.keys.Enter):
+			if e.focusIndex == len(e.inputs)-1 || key.Matches(msg, e.keys.Save) {
 				// Save configuration
 				if err := e.saveConfig(); err != nil {
 					e.err = err
@@ -147,6 +199,16 @@ func (e *ConfigEditor) View() string {
 	
 	s := titleStyle.Render("Configure add-n-commit") + "\n\n"
 	
+	profile := e.profile
+	if profile == "" {
+		profile = "default"
+	}
+	s += labelStyle.Render("Profile: ") + profile + "\n"
+	if e.profile != "" {
+		s += helpStyle.Render("Empty fields inherit the default settings") + "\n"
+	}
+	s += "\n"
+	
 	labels := []string{
 		"OpenAI API Key:",
 		"Model:",
@@ -154,6 +216,8 @@ func (e *ConfigEditor) View() string {
 		"Temperature:",
 		"System Prompt (All):",
 		"System Prompt (File):",
+		"Provider:",
+		"Base URL:",
 	}
 	
 	for i, input := range e.inputs {
@@ -161,11 +225,24 @@ func (e *ConfigEditor) View() string {
 		s += input.View() + "\n\n"
 	}
 	
-	s += helpStyle.Render("Tab/↓: next field • Shift+Tab/↑: previous field • Ctrl+S: save • Esc: cancel")
+	s += e.viewHelp()
 	
 	return s
 }
 
+// viewHelp lists the editor's keys, following the user's key bindings
+func (e *ConfigEditor) viewHelp() string {
+	cancel := e.keys.Back
+	cancel.SetHelp(cancel.Help().Key, "cancel")
+	return e.help.ShortHelpView([]key.Binding{
+		e.keys.NextField,
+		e.keys.PrevField,
+		e.keys.NextProfile,
+		e.keys.Save,
+		cancel,
+	})
+}
+
 func (e *ConfigEditor) updateFocus() {
 	for i := range e.inputs {
 		if i == e.focusIndex {
@@ -186,27 +263,104 @@ func (e *ConfigEditor) updateInputs(msg tea.Msg) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
-func (e *ConfigEditor) saveConfig() error {
-	// Update config from inputs
-	e.config.OpenAIKey = e.inputs[0].Value()
-	e.config.Model = e.inputs[1].Value()
+// loadValues fills the inputs from the profile being edited
+func (e *ConfigEditor) loadValues() {
+	e.inputs[2].SetValue(e.config.DefaultMode)
+	
+	// Keys kept in a secret store aren't shown; typing a new one replaces it
+	e.inputs[0].Placeholder = "sk-..."
+	if e.config.KeyBackend != "" && e.config.KeyBackend != "config" {
+		e.inputs[0].Placeholder = "Stored in " + e.config.KeyBackend + " (type to replace)"
+	}
+	
+	if e.profile == "" {
+		e.inputs[0].SetValue(e.config.OpenAIKey)
+		e.inputs[1].SetValue(e.config.Model)
+		e.inputs[3].SetValue(fmt.Sprintf("%.1f", e.config.Temperature))
+		e.inputs[4].SetValue(e.config.SystemPromptAll)
+		e.inputs[5].SetValue(e.config.SystemPromptFile)
+		e.inputs[6].SetValue(e.config.Provider)
+		e.inputs[7].SetValue(e.config.BaseURL)
+		return
+	}
+	
+	p := e.config.Profiles[e.profile]
+	e.inputs[0].SetValue(p.OpenAIKey)
+	e.inputs[1].SetValue(p.Model)
+	e.inputs[3].SetValue("")
+	if p.Temperature != nil {
+		e.inputs[3].SetValue(fmt.Sprintf("%.1f", *p.Temperature))
+	}
+	e.inputs[4].SetValue(p.SystemPromptAll)
+	e.inputs[5].SetValue(p.SystemPromptFile)
+	e.inputs[6].SetValue(p.Provider)
+	e.inputs[7].SetValue(p.BaseURL)
+}
+
+// storeValues copies the inputs into the profile being edited
+func (e *ConfigEditor) storeValues() error {
 	e.config.DefaultMode = e.inputs[2].Value()
 	
-	// Parse temperature
-	temp, err := strconv.ParseFloat(e.inputs[3].Value(), 32)
-	if err != nil {
-		return fmt.Errorf("invalid temperature value: %w", err)
+	provider := e.inputs[6].Value()
+	if provider != "" && provider != ProviderOpenAI && provider != ProviderOllama {
+		return fmt.Errorf("invalid provider: must be '%s' or '%s'", ProviderOpenAI, ProviderOllama)
+	}
+	
+	if e.profile == "" {
+		// Parse temperature
+		temp, err := strconv.ParseFloat(e.inputs[3].Value(), 32)
+		if err != nil {
+			return fmt.Errorf("invalid temperature value: %w", err)
+		}
+		
+		e.config.OpenAIKey = e.inputs[0].Value()
+		e.config.Model = e.inputs[1].Value()
+		e.config.Temperature = float32(temp)
+		e.config.SystemPromptAll = e.inputs[4].Value()
+		e.config.SystemPromptFile = e.inputs[5].Value()
+		e.config.Provider = provider
+		e.config.BaseURL = e.inputs[7].Value()
+		return nil
 	}
-	e.config.Temperature = float32(temp)
 	
-	e.config.SystemPromptAll = e.inputs[4].Value()
-	e.config.SystemPromptFile = e.inputs[5].Value()
+	p := e.config.Profiles[e.profile]
+	p.Temperature = nil
+	if value := e.inputs[3].Value(); value != "" {
+		temp, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return fmt.Errorf("invalid temperature value: %w", err)
+		}
+		t := float32(temp)
+		p.Temperature = &t
+	}
+	
+	p.OpenAIKey = e.inputs[0].Value()
+	p.Model = e.inputs[1].Value()
+	p.SystemPromptAll = e.inputs[4].Value()
+	p.SystemPromptFile = e.inputs[5].Value()
+	p.Provider = provider
+	p.BaseURL = e.inputs[7].Value()
+	return nil
+}
+
+func (e *ConfigEditor) saveConfig() error {
+	// Update config from inputs
+	if err := e.storeValues(); err != nil {
+		return err
+	}
+	e.config.ActiveProfile = e.profile
 	
 	// Validate default mode
 	if e.config.DefaultMode != "interactive" && 
 	   e.config.DefaultMode != "all" && 
-	   e.config.DefaultMode != "by-file" {
-		return fmt.Errorf("invalid default mode: must be 'interactive', 'all', or 'by-file'")
+	   e.config.DefaultMode != "by-file" &&
+	   e.config.DefaultMode != "conventional" {
+		return fmt.Errorf("invalid default mode: must be 'interactive', 'all', 'by-file', or 'conventional'")
+	}
+	
+	// Move any newly entered keys into the secret store before saving
+	if err := e.config.secureKeys(); err != nil {
+		return err
 	}
 	
 	// Save to file
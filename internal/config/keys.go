@@ -0,0 +1,236 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/oconnorjohnson/add-n-commit/internal/secret"
+)
+
+// DefaultAccount is the secret store account for the key outside profiles
+const DefaultAccount = "default"
+
+// stores caches opened secret stores so a passphrase is asked only once
+var stores = map[string]secret.Store{}
+
+// SecretStore returns the configured secret store, or nil when keys are
+// kept in plaintext in config.json
+func (c *Config) SecretStore() (secret.Store, error) {
+	if store, ok := stores[c.KeyBackend]; ok {
+		return store, nil
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := secret.Open(c.KeyBackend, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	stores[c.KeyBackend] = store
+	return store, nil
+}
+
+// UnlockSecretStore asks for anything the secret store needs, such as the
+// passphrase of the file backend, before a TUI takes over the terminal.
+// Saving keys from the TUI would otherwise prompt in the middle of it.
+func (c *Config) UnlockSecretStore() error {
+	store, err := c.SecretStore()
+	if err != nil || store == nil {
+		return err
+	}
+	if unlocker, ok := store.(secret.Unlocker); ok {
+		return unlocker.Unlock()
+	}
+	return nil
+}
+
+// APIKey resolves the API key and describes where it came from. The
+// environment wins over the active profile's own key, then api_key_command,
+// a plaintext key in config.json and finally the secret store.
+func (c *Config) APIKey() (string, string, error) {
+	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+		return key, "environment variable OPENAI_API_KEY", nil
+	}
+
+	// A profile key kept in the secret store wins over inherited
+	// credentials. Keys and commands set in the profile itself were
+	// already applied over the base ones.
+	if p := c.Profiles[c.ActiveProfile]; p != nil && p.OpenAIKey == "" && p.APIKeyCommand == "" {
+		store, err := c.SecretStore()
+		if err != nil {
+			return "", "", err
+		}
+		if store != nil {
+			key, err := store.Get(c.ActiveProfile)
+			if err == nil {
+				return key, fmt.Sprintf("%s (account %q)", store.Name(), c.ActiveProfile), nil
+			}
+			if !errors.Is(err, secret.ErrNotFound) {
+				return "", "", err
+			}
+		}
+	}
+
+	if c.APIKeyCommand != "" {
+		key, err := secret.RunKeyCommand(c.APIKeyCommand)
+		if err != nil {
+			return "", "", err
+		}
+		return key, "command: " + c.APIKeyCommand, nil
+	}
+
+	if c.OpenAIKey != "" {
+		return c.OpenAIKey, "plaintext in ~/.config/anc/config.json", nil
+	}
+
+	store, err := c.SecretStore()
+	if err != nil || store == nil {
+		return "", "", err
+	}
+
+	accounts := []string{DefaultAccount}
+	if c.ActiveProfile != "" {
+		accounts = []string{c.ActiveProfile, DefaultAccount}
+	}
+	for _, account := range accounts {
+		key, err := store.Get(account)
+		if err == nil {
+			return key, fmt.Sprintf("%s (account %q)", store.Name(), account), nil
+		}
+		if !errors.Is(err, secret.ErrNotFound) {
+			return "", "", err
+		}
+	}
+
+	return "", "", nil
+}
+
+// SetAPIKey stores the default API key in the configured backend and
+// saves the configuration
+func (c *Config) SetAPIKey(key string) error {
+	c.OpenAIKey = key
+	if err := c.secureKeys(); err != nil {
+		return err
+	}
+	return c.Save()
+}
+
+// DeleteAPIKey removes the default API key from config.json and the
+// configured secret store
+func (c *Config) DeleteAPIKey() error {
+	store, err := c.SecretStore()
+	if err != nil {
+		return err
+	}
+
+	if store != nil {
+		if err := store.Delete(DefaultAccount); err != nil && !errors.Is(err, secret.ErrNotFound) {
+			return fmt.Errorf("failed to delete key from %s: %w", store.Name(), err)
+		}
+	}
+
+	c.OpenAIKey = ""
+	return c.Save()
+}
+
+// MigrateAPIKeys moves the default and profile keys from their current
+// location into the given backend, switches to it and saves the
+// configuration. It returns the number of keys moved.
+func (c *Config) MigrateAPIKeys(backend string) (int, error) {
+	from, err := c.SecretStore()
+	if err != nil {
+		return 0, err
+	}
+
+	previous := c.KeyBackend
+	c.KeyBackend = backend
+	to, err := c.SecretStore()
+	if err != nil {
+		c.KeyBackend = previous
+		return 0, err
+	}
+
+	moved := 0
+	var migrated []string
+	for _, account := range append([]string{DefaultAccount}, c.ProfileNames()...) {
+		key := c.plaintextKey(account)
+		if key == "" && from != nil {
+			if key, err = from.Get(account); errors.Is(err, secret.ErrNotFound) {
+				continue
+			} else if err != nil {
+				return moved, err
+			}
+		}
+		if key == "" {
+			continue
+		}
+
+		if to == nil {
+			c.setPlaintextKey(account, key)
+		} else {
+			if err := to.Set(account, key); err != nil {
+				return moved, fmt.Errorf("failed to store key in %s: %w", to.Name(), err)
+			}
+			c.setPlaintextKey(account, "")
+		}
+		migrated = append(migrated, account)
+		moved++
+	}
+
+	if err := c.Save(); err != nil {
+		return moved, err
+	}
+
+	// Only remove the old copies once the new location is saved
+	if from != nil && from != to {
+		for _, account := range migrated {
+			from.Delete(account)
+		}
+	}
+
+	return moved, nil
+}
+
+// secureKeys moves plaintext keys into the configured secret store
+func (c *Config) secureKeys() error {
+	store, err := c.SecretStore()
+	if err != nil || store == nil {
+		return err
+	}
+
+	for _, account := range append([]string{DefaultAccount}, c.ProfileNames()...) {
+		key := c.plaintextKey(account)
+		if key == "" {
+			continue
+		}
+		if err := store.Set(account, key); err != nil {
+			return fmt.Errorf("failed to store key in %s: %w", store.Name(), err)
+		}
+		c.setPlaintextKey(account, "")
+	}
+
+	return nil
+}
+
+func (c *Config) plaintextKey(account string) string {
+	if account == DefaultAccount {
+		return c.OpenAIKey
+	}
+	if p := c.Profiles[account]; p != nil {
+		return p.OpenAIKey
+	}
+	return ""
+}
+
+func (c *Config) setPlaintextKey(account, key string) {
+	if account == DefaultAccount {
+		c.OpenAIKey = key
+	} else if p := c.Profiles[account]; p != nil {
+		p.OpenAIKey = key
+	}
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Supported providers
+const (
+	ProviderOpenAI = "openai"
+	ProviderOllama = "ollama" // Local OpenAI-compatible server
+)
+
+// DefaultOllamaURL is the OpenAI-compatible endpoint of a local Ollama server
+const DefaultOllamaURL = "http://localhost:11434/v1"
+
+// Profile is a named set of settings layered over the base configuration.
+// Empty fields inherit the base value.
+type Profile struct {
+	Provider                 string   `json:"provider,omitempty"`
+	OpenAIKey                string   `json:"openai_key,omitempty"`
+	APIKeyCommand            string   `json:"api_key_command,omitempty"`
+	BaseURL                  string   `json:"base_url,omitempty"`
+	Model                    string   `json:"model,omitempty"`
+	Temperature              *float32 `json:"temperature,omitempty"`
+	SystemPromptAll          string   `json:"system_prompt_all,omitempty"`
+	SystemPromptFile         string   `json:"system_prompt_file,omitempty"`
+	SystemPromptConventional string   `json:"system_prompt_conventional,omitempty"`
+	SystemPromptSplit        string   `json:"system_prompt_split,omitempty"`
+}
+
+// ProfileNames returns the names of the configured profiles, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile returns a copy of the configuration with the named profile
+// applied. Profiles always apply to the configuration they were first
+// selected on, so switching from one profile to another doesn't mix them.
+// An empty name selects no profile.
+func (c *Config) WithProfile(name string) (*Config, error) {
+	base := c
+	if c.profileBase != nil {
+		base = c.profileBase
+	}
+
+	profile, ok := base.Profiles[name]
+	if name != "" && (!ok || profile == nil) {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+
+	layered, err := base.clone()
+	if err != nil {
+		return nil, err
+	}
+
+	if profile != nil {
+		profile.applyTo(layered)
+	}
+	layered.ActiveProfile = name
+	layered.user = base.User()
+	layered.projectFile = base.projectFile
+	layered.profileBase = base
+	return layered, nil
+}
+
+// Endpoint returns the API base URL for the configured provider, or an
+// empty string for the OpenAI default
+func (c *Config) Endpoint() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	if c.Provider == ProviderOllama {
+		return DefaultOllamaURL
+	}
+	return ""
+}
+
+// NeedsAPIKey reports whether the provider requires an API key
+func (c *Config) NeedsAPIKey() bool {
+	return c.Provider != ProviderOllama
+}
+
+func (p *Profile) applyTo(c *Config) {
+	if p.Provider != "" {
+		c.Provider = p.Provider
+	}
+	// A profile's own credential replaces both inherited ones, otherwise
+	// an inherited api_key_command would win over the profile's key
+	if p.OpenAIKey != "" {
+		c.OpenAIKey = p.OpenAIKey
+		c.APIKeyCommand = ""
+	}
+	if p.APIKeyCommand != "" {
+		c.APIKeyCommand = p.APIKeyCommand
+		c.OpenAIKey = ""
+	}
+	if p.BaseURL != "" {
+		c.BaseURL = p.BaseURL
+	}
+	if p.Model != "" {
+		c.Model = p.Model
+	}
+	if p.Temperature != nil {
+		c.Temperature = *p.Temperature
+	}
+	if p.SystemPromptAll != "" {
+		c.SystemPromptAll = p.SystemPromptAll
+	}
+	if p.SystemPromptFile != "" {
+		c.SystemPromptFile = p.SystemPromptFile
+	}
+	if p.SystemPromptConventional != "" {
+		c.SystemPromptConventional = p.SystemPromptConventional
+	}
+	if p.SystemPromptSplit != "" {
+		c.SystemPromptSplit = p.SystemPromptSplit
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFiles are the repository config file names, in order of preference
+var ProjectFiles = []string{".anc.json", ".anc.yaml", ".anc.yml"}
+
+// projectKeys are the settings a committed project file may set. Anything
+// touching credentials, endpoints, providers, profile definitions or
+// signing could leak the user's key or run commands from repository
+// content, so project files are limited to how messages are written and
+// to picking one of the user's own profiles by name.
+var projectKeys = map[string]bool{
+	"profile":                    true,
+	"system_prompt_all":          true,
+	"system_prompt_file":         true,
+	"system_prompt_conventional": true,
+	"system_prompt_split":        true,
+	"conventional_types":         true,
+	"conventional_scopes":        true,
+	"conventional_strict":        true,
+	"lint":                       true,
+	"llm_exclude":                true,
+	"style_examples":             true,
+	"style_examples_by_path":     true,
+	"ticket_pattern":             true,
+	"ticket_placement":           true,
+	"ticket_trailer":             true,
+}
+
+// FindProjectFile returns the path of the project config file at the
+// repository root, or an empty string if there is none
+func FindProjectFile(root string) string {
+	for _, name := range ProjectFiles {
+		path := filepath.Join(root, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// WithProject returns a copy of the configuration with the project config
+// found at the repository root layered on top. Settings missing from the
+// project file keep their user values. If there is no project file the
+// configuration is returned unchanged.
+func (c *Config) WithProject(root string) (*Config, error) {
+	path := FindProjectFile(root)
+	if path == "" {
+		return c, nil
+	}
+
+	overrides, err := readProjectFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project config %s: %w", path, err)
+	}
+
+	layered, err := c.clone()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(overrides, layered); err != nil {
+		return nil, fmt.Errorf("invalid project config %s: %w", path, err)
+	}
+
+	layered.user = c.User()
+	layered.projectFile = path
+	return layered, nil
+}
+
+// ProjectFile returns the path of the project config applied to this
+// configuration, if any
+func (c *Config) ProjectFile() string {
+	return c.projectFile
+}
+
+// User returns the user-level configuration underneath any project
+// overrides. Changes that should be persisted with Save belong here.
+func (c *Config) User() *Config {
+	if c.user != nil {
+		return c.user
+	}
+	return c
+}
+
+// readProjectFile reads a JSON or YAML project file and returns it as JSON
+// so it can be decoded with the same field names as the user config
+func readProjectFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if strings.HasSuffix(path, ".json") {
+		err = json.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rejected []string
+	for key := range values {
+		if !projectKeys[key] {
+			rejected = append(rejected, key)
+		}
+	}
+	if len(rejected) > 0 {
+		sort.Strings(rejected)
+		return nil, fmt.Errorf("settings not allowed in project files: %s", strings.Join(rejected, ", "))
+	}
+
+	return json.Marshal(values)
+}
+
+func (c *Config) clone() (*Config, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &Config{}
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
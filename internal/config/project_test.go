@@ -0,0 +1,131 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeProject writes a project file into a new repository root
+func writeProject(t *testing.T, name, content string) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func userConfig() *Config {
+	cfg := Default()
+	cfg.Model = "gpt-4o"
+	cfg.Profiles = map[string]*Profile{
+		"work":    {Model: "gpt-4o-mini"},
+		"offline": {Provider: ProviderOllama, Model: "llama3"},
+	}
+	return cfg
+}
+
+func TestWithProjectSelectsProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		user    string
+		want    string
+		model   string
+	}{
+		{
+			name:    "json project file",
+			file:    ".anc.json",
+			content: `{"profile": "work"}`,
+			want:    "work",
+			model:   "gpt-4o-mini",
+		},
+		{
+			name:    "yaml project file",
+			file:    ".anc.yaml",
+			content: "profile: offline\n",
+			want:    "offline",
+			model:   "llama3",
+		},
+		{
+			name:    "project overrides the user's profile",
+			file:    ".anc.json",
+			content: `{"profile": "offline"}`,
+			user:    "work",
+			want:    "offline",
+			model:   "llama3",
+		},
+		{
+			name:    "user's profile is kept without one in the project",
+			file:    ".anc.json",
+			content: `{"conventional_strict": true}`,
+			user:    "work",
+			want:    "work",
+			model:   "gpt-4o-mini",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := userConfig()
+			cfg.ActiveProfile = tt.user
+
+			layered, err := cfg.WithProject(writeProject(t, tt.file, tt.content))
+			if err != nil {
+				t.Fatalf("WithProject: %v", err)
+			}
+			if layered.ActiveProfile != tt.want {
+				t.Fatalf("ActiveProfile = %q, want %q", layered.ActiveProfile, tt.want)
+			}
+			if layered.User().ActiveProfile != tt.user {
+				t.Errorf("user ActiveProfile = %q, want %q", layered.User().ActiveProfile, tt.user)
+			}
+
+			selected, err := layered.WithProfile(layered.ActiveProfile)
+			if err != nil {
+				t.Fatalf("WithProfile: %v", err)
+			}
+			if selected.Model != tt.model {
+				t.Errorf("Model = %q, want %q", selected.Model, tt.model)
+			}
+		})
+	}
+}
+
+func TestWithProjectRejectsSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		keys    string
+	}{
+		{"api key", `{"openai_key": "sk-test"}`, "openai_key"},
+		{"key command", `{"api_key_command": "curl evil"}`, "api_key_command"},
+		{"profile definitions", `{"profile": "work", "profiles": {"work": {"base_url": "http://evil"}}}`, "profiles"},
+		{"several settings", `{"provider": "ollama", "base_url": "http://evil"}`, "base_url, provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := userConfig().WithProject(writeProject(t, ".anc.json", tt.content))
+			if err == nil {
+				t.Fatal("WithProject succeeded, want an error")
+			}
+			if !strings.Contains(err.Error(), "not allowed in project files: "+tt.keys) {
+				t.Errorf("error = %v, want it to list %s", err, tt.keys)
+			}
+		})
+	}
+}
+
+func TestWithProjectUnknownProfile(t *testing.T) {
+	layered, err := userConfig().WithProject(writeProject(t, ".anc.json", `{"profile": "missing"}`))
+	if err != nil {
+		t.Fatalf("WithProject: %v", err)
+	}
+	if _, err := layered.WithProfile(layered.ActiveProfile); err == nil {
+		t.Error("WithProfile succeeded for a profile the user doesn't have")
+	}
+}
@@ -0,0 +1,136 @@
+package draft
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Draft is a commit message saved for a staged tree
+type Draft struct {
+	Tree      string    `json:"tree"` // git write-tree of the index
+	Message   string    `json:"message"`
+	Mode      string    `json:"mode,omitempty"` // Generation mode, for validation on resume
+	Branch    string    `json:"branch,omitempty"`
+	Saved     time.Time `json:"saved"`
+	Committed bool      `json:"committed,omitempty"` // Kept as history only
+}
+
+// Store keeps drafts as one JSON file per tree, pruned to the newest limit
+type Store struct {
+	dir   string
+	limit int
+}
+
+// Open returns the draft store under gitDir/anc/drafts
+func Open(gitDir string, limit int) *Store {
+	return &Store{
+		dir:   filepath.Join(gitDir, "anc", "drafts"),
+		limit: limit,
+	}
+}
+
+// Save writes a draft, replacing any earlier draft for the same tree, and
+// prunes the oldest drafts beyond the limit
+func (s *Store) Save(d Draft) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create draft directory: %w", err)
+	}
+
+	if d.Saved.IsZero() {
+		d.Saved = time.Now()
+	}
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write through a temporary file so a crash never leaves half a draft
+	path := s.path(d.Tree)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to save draft: %w", err)
+	}
+
+	return s.prune()
+}
+
+// Load returns the draft for a tree, or nil if there is none
+func (s *Store) Load(tree string) (*Draft, error) {
+	data, err := os.ReadFile(s.path(tree))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+
+	var d Draft
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, fmt.Errorf("failed to read draft: %w", err)
+	}
+	return &d, nil
+}
+
+// List returns all drafts, newest first
+func (s *Store) List() ([]Draft, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drafts: %w", err)
+	}
+
+	var drafts []Draft
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		d, err := s.Load(strings.TrimSuffix(name, ".json"))
+		if err != nil || d == nil {
+			continue
+		}
+		drafts = append(drafts, *d)
+	}
+
+	sort.Slice(drafts, func(i, j int) bool {
+		return drafts[i].Saved.After(drafts[j].Saved)
+	})
+	return drafts, nil
+}
+
+// Delete removes the draft for a tree
+func (s *Store) Delete(tree string) error {
+	err := os.Remove(s.path(tree))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) prune() error {
+	drafts, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i := s.limit; i < len(drafts); i++ {
+		if err := s.Delete(drafts[i].Tree); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) path(tree string) string {
+	return filepath.Join(s.dir, tree+".json")
+}
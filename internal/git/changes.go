@@ -0,0 +1,146 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// EmptyTree is the hash of git's empty tree, used as the base for diffs of
+// root commits
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// Changes selects a set of changes to describe. The zero value is the
+// staged changes against HEAD.
+type Changes struct {
+	Base   string // Commit to compare against, HEAD when empty
+	Commit string // Compare Base with this commit instead of the index
+}
+
+// Files returns the paths changed
+func (c Changes) Files() ([]string, error) {
+	output, err := exec.Command("git", c.args("--name-only")...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+
+	var files []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// Diff returns the diff of the changes, limited to files if any are given
+func (c Changes) Diff(files ...string) (string, error) {
+	output, err := exec.Command("git", c.args(append([]string{"--"}, files...)...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff: %w", err)
+	}
+	return string(output), nil
+}
+
+// DiffExcluding returns the diff with the diffs of files matching exclude
+// replaced by a one-line stat summary each
+func (c Changes) DiffExcluding(exclude *PathMatcher) (string, error) {
+	files, err := c.Files()
+	if err != nil {
+		return "", err
+	}
+
+	var included, excluded []string
+	for _, file := range files {
+		if exclude.Match(file) {
+			excluded = append(excluded, file)
+		} else {
+			included = append(included, file)
+		}
+	}
+
+	if len(excluded) == 0 {
+		return c.Diff()
+	}
+
+	var diff string
+	if len(included) > 0 {
+		if diff, err = c.Diff(included...); err != nil {
+			return "", err
+		}
+	}
+
+	summary, err := c.Summary(excluded)
+	if err != nil {
+		return "", err
+	}
+
+	return diff + "\nChanges to the following files are summarized, their diffs were omitted:\n" + summary, nil
+}
+
+// Summary returns one "path | +added -deleted lines" line per file
+func (c Changes) Summary(files []string) (string, error) {
+	output, err := exec.Command("git", c.args(append([]string{"--numstat", "--"}, files...)...)...).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff stats: %w", err)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "-" {
+			lines = append(lines, fmt.Sprintf("%s | binary file changed", fields[2]))
+		} else {
+			lines = append(lines, fmt.Sprintf("%s | +%s -%s lines", fields[2], fields[0], fields[1]))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+func (c Changes) args(extra ...string) []string {
+	args := []string{"diff"}
+	if c.Commit != "" {
+		args = append(args, c.Base, c.Commit)
+	} else {
+		args = append(args, "--cached")
+		if c.Base != "" {
+			args = append(args, c.Base)
+		}
+	}
+	return append(args, extra...)
+}
+
+// ParentOf returns the first parent of a commit, or the empty tree when
+// the commit is a root commit
+func ParentOf(rev string) (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--verify", "-q", rev+"^").Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return EmptyTree, nil
+		}
+		return "", fmt.Errorf("failed to resolve parent of %s: %w", rev, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsPushed reports whether a commit is already contained in the upstream
+// of the current branch, and returns the upstream's name
+func IsPushed(rev string) (bool, string, error) {
+	upstream := GetUpstream()
+	if upstream == "" {
+		return false, "", nil
+	}
+
+	err := exec.Command("git", "merge-base", "--is-ancestor", rev, "@{u}").Run()
+	if err == nil {
+		return true, upstream, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, upstream, nil
+	}
+	return false, upstream, fmt.Errorf("failed to compare %s with %s: %w", rev, upstream, err)
+}
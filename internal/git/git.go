@@ -3,6 +3,7 @@ package git
 import (
 	"bytes"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -15,6 +16,63 @@ type File struct {
 	IsTracked bool
 }
 
+// GetRepoRoot returns the top-level directory of the repository
+func GetRepoRoot() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository root: %w", err)
+	}
+	
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetGitDir returns the absolute path of the repository's .git directory
+func GetGitDir() (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get git directory: %w", err)
+	}
+	
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetEditor returns the editor command git uses for commit messages, from
+// $GIT_EDITOR, core.editor, $VISUAL or $EDITOR
+func GetEditor() (string, error) {
+	output, err := exec.Command("git", "var", "GIT_EDITOR").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find an editor, set core.editor or $EDITOR: %w", err)
+	}
+	
+	return strings.TrimSpace(string(output)), nil
+}
+
+// WriteTree writes the index as a tree object and returns its hash, which
+// identifies exactly what is staged
+func WriteTree() (string, error) {
+	output, err := exec.Command("git", "write-tree").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to write index tree: %w", err)
+	}
+	
+	return strings.TrimSpace(string(output)), nil
+}
+
+// ReadTree replaces the index with a tree written by WriteTree, leaving
+// the working tree untouched
+func ReadTree(tree string) error {
+	if output, err := exec.Command("git", "read-tree", tree).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore index: %w\n%s", err, output)
+	}
+	
+	// read-tree drops the cached file stats, refresh them so later commands
+	// don't hash every file again. Refreshing reports files that differ
+	// from the index as an error, which is expected.
+	exec.Command("git", "update-index", "-q", "--refresh").Run()
+	return nil
+}
+
 // GetStatus returns the current git status
 func GetStatus() ([]File, error) {
 	cmd := exec.Command("git", "status", "--porcelain", "-uall")
@@ -121,6 +179,32 @@ func GetStagedDiffForFile(file string) (string, error) {
 	return string(output), nil
 }
 
+// GetWorktreeDiff returns the diff of a file's working tree against HEAD,
+// staged or not. Untracked files are shown as new files.
+func GetWorktreeDiff(file File) (string, error) {
+	var cmd *exec.Cmd
+	if file.Status == "??" {
+		cmd = exec.Command("git", "diff", "--no-index", "--", os.DevNull, file.Path)
+	} else {
+		base := "HEAD"
+		if err := exec.Command("git", "rev-parse", "--verify", "-q", "HEAD").Run(); err != nil {
+			base = EmptyTree
+		}
+		cmd = exec.Command("git", "diff", base, "--", file.Path)
+	}
+	
+	output, err := cmd.Output()
+	// --no-index exits with 1 when the files differ, which they always do
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && len(output) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff for %s: %w", file.Path, err)
+	}
+	
+	return string(output), nil
+}
+
 // GetStagedFiles returns a list of staged files
 func GetStagedFiles() ([]string, error) {
 	cmd := exec.Command("git", "diff", "--cached", "--name-only")
@@ -140,20 +224,79 @@ func GetStagedFiles() ([]string, error) {
 	return files, nil
 }
 
+// CommitOptions controls how a commit is created
+type CommitOptions struct {
+	Amend      bool   // Replace HEAD instead of creating a new commit
+	Sign       bool   // Sign the commit, with SigningKey if set
+	SigningKey string // Overrides user.signingKey
+	NoSign     bool   // Don't sign, even if commit.gpgSign is set
+	NoVerify   bool   // Skip the pre-commit and commit-msg hooks
+}
+
 // Commit creates a commit with the given message
 func Commit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+	return CommitWithOptions(message, CommitOptions{})
+}
+
+// CommitWithOptions creates a commit with the given message and options
+func CommitWithOptions(message string, opts CommitOptions) error {
+	return CommitStreaming(message, opts, nil)
+}
+
+// CommitStreaming creates a commit like CommitWithOptions, passing the
+// output of git and its hooks to onLine as it arrives
+func CommitStreaming(message string, opts CommitOptions, onLine func(line string, replace bool)) error {
+	args := []string{"commit", "-m", message}
+	if opts.Amend {
+		args = append(args, "--amend")
+	}
+	if opts.NoVerify {
+		args = append(args, "--no-verify")
+	}
+	args = append(args, opts.signArgs()...)
 	
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to commit: %w\n%s", err, stderr.String())
+	output, err := streamCommand(exec.Command("git", args...), onLine)
+	if err != nil {
+		if signErr := signingError(output); signErr != nil {
+			return signErr
+		}
+		return fmt.Errorf("failed to commit: %w\n%s", err, output)
 	}
 	
 	return nil
 }
 
+// HashWorktreeFiles returns the blob hashes of the files in the working
+// tree and writes the blobs, so RestageChanges can merge from them later.
+// Missing files are left out.
+func HashWorktreeFiles(files []string) (map[string]string, error) {
+	var existing []string
+	for _, file := range files {
+		if _, err := os.Lstat(file); err == nil {
+			existing = append(existing, file)
+		}
+	}
+	
+	hashes := map[string]string{}
+	if len(existing) == 0 {
+		return hashes, nil
+	}
+	
+	args := append([]string{"hash-object", "-w", "--"}, existing...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash files: %w", err)
+	}
+	
+	for i, hash := range strings.Fields(string(output)) {
+		if i < len(existing) {
+			hashes[existing[i]] = hash
+		}
+	}
+	
+	return hashes, nil
+}
+
 // GetLastCommitMessage returns the last commit message
 func GetLastCommitMessage() (string, error) {
 	cmd := exec.Command("git", "log", "-1", "--pretty=%B")
@@ -163,4 +306,127 @@ func GetLastCommitMessage() (string, error) {
 	}
 	
 	return strings.TrimSpace(string(output)), nil
-} 
\ No newline at end of file
+} 
+
+// CommitInfo is a commit's abbreviated hash and subject
+type CommitInfo struct {
+	Hash    string
+	Subject string
+}
+
+// GetLog returns the last n commits reachable from HEAD, newest first
+func GetLog(n int) ([]CommitInfo, error) {
+	cmd := exec.Command("git", "log", "-n", fmt.Sprint(n), "--format=%h%x00%s")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get log: %w", err)
+	}
+	
+	var commits []CommitInfo
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if hash, subject, ok := strings.Cut(line, "\x00"); ok {
+			commits = append(commits, CommitInfo{Hash: hash, Subject: subject})
+		}
+	}
+	
+	return commits, nil
+}
+
+// GetRecentCommitMessages returns up to n recent non-merge commit messages,
+// newest first. If paths is not empty only commits touching them are used.
+func GetRecentCommitMessages(n int, paths []string) ([]string, error) {
+	args := []string{"log", "-n", fmt.Sprint(n), "--no-merges", "--format=%B%x00"}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent commit messages: %w", err)
+	}
+	
+	var messages []string
+	for _, msg := range strings.Split(string(output), "\x00") {
+		if msg = strings.TrimSpace(msg); msg != "" {
+			messages = append(messages, msg)
+		}
+	}
+	
+	return messages, nil
+}
+
+// GetCurrentBranch returns the short name of the checked out branch, or an
+// empty string when HEAD is detached
+func GetCurrentBranch() (string, error) {
+	cmd := exec.Command("git", "symbolic-ref", "--short", "-q", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get current branch: %w", err)
+	}
+	
+	return strings.TrimSpace(string(output)), nil
+}
+
+// InterpretTrailers adds "Key: value" trailers to a commit message using
+// git interpret-trailers, skipping trailers the message already has
+func InterpretTrailers(message string, trailers []string) (string, error) {
+	if len(trailers) == 0 {
+		return message, nil
+	}
+	
+	args := []string{"interpret-trailers", "--if-exists", "addIfDifferent"}
+	for _, trailer := range trailers {
+		args = append(args, "--trailer", trailer)
+	}
+	
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(message)
+	
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to add trailers: %w\n%s", err, stderr.String())
+	}
+	
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+// GetIdentity returns the configured committer as "Name <email>"
+func GetIdentity() (string, error) {
+	name, err := exec.Command("git", "config", "user.name").Output()
+	if err != nil {
+		return "", fmt.Errorf("user.name is not configured: %w", err)
+	}
+	
+	email, err := exec.Command("git", "config", "user.email").Output()
+	if err != nil {
+		return "", fmt.Errorf("user.email is not configured: %w", err)
+	}
+	
+	return fmt.Sprintf("%s <%s>", strings.TrimSpace(string(name)), strings.TrimSpace(string(email))), nil
+}
+
+// GetAuthors returns the repository's authors as "Name <email>", most
+// active first. Identities are merged according to .mailmap.
+func GetAuthors() ([]string, error) {
+	cmd := exec.Command("git", "shortlog", "-sne", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authors: %w", err)
+	}
+	
+	var authors []string
+	for _, line := range strings.Split(string(output), "\n") {
+		// Format: "   12\tName <email>"
+		if _, author, ok := strings.Cut(line, "\t"); ok && author != "" {
+			authors = append(authors, author)
+		}
+	}
+	
+	return authors, nil
+}
@@ -0,0 +1,189 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Hunk is one hunk of a diff together with the header of its file, so it
+// can be applied on its own
+type Hunk struct {
+	File   string
+	Header string // "diff --git" line up to the first "@@"
+	Body   string // The "@@" line and its content, empty for binary files
+}
+
+// Patch returns the hunk as a patch that git apply accepts
+func (h Hunk) Patch() string {
+	return h.Header + h.Body
+}
+
+// GetStagedHunks returns the staged changes split into hunks. Renames are
+// reported as a deletion and an addition so every hunk applies on its own.
+func GetStagedHunks() ([]Hunk, error) {
+	cmd := exec.Command("git", "diff", "--cached", "--binary", "--no-color", "--no-ext-diff", "--no-renames")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get staged diff: %w", err)
+	}
+	return ParseHunks(string(output)), nil
+}
+
+// ParseHunks splits a unified diff into hunks. Added, deleted and binary
+// files and files whose mode changed are kept as a single hunk, since
+// their headers can only be applied once.
+func ParseHunks(diff string) []Hunk {
+	var hunks []Hunk
+	for _, section := range splitFiles(diff) {
+		file := ""
+		firstLine := section[:strings.Index(section+"\n", "\n")]
+		if idx := strings.LastIndex(firstLine, " b/"); idx >= 0 {
+			file = firstLine[idx+3:]
+		}
+
+		start := hunkStart(section, 0)
+		if start < 0 || !splittable(section[:start]) {
+			hunks = append(hunks, Hunk{File: file, Header: section})
+			continue
+		}
+
+		header := section[:start]
+		for start >= 0 {
+			next := hunkStart(section, start+1)
+			end := next
+			if end < 0 {
+				end = len(section)
+			}
+			hunks = append(hunks, Hunk{File: file, Header: header, Body: section[start:end]})
+			start = next
+		}
+	}
+	return hunks
+}
+
+// BuildPatch joins hunks into one patch, grouping hunks of the same file
+// under a single header. Hunks of a file are put back in their order in the
+// file, since git apply expects them sorted.
+func BuildPatch(hunks []Hunk) string {
+	var order []string
+	byFile := map[string][]Hunk{}
+	for _, h := range hunks {
+		if _, ok := byFile[h.File]; !ok {
+			order = append(order, h.File)
+		}
+		byFile[h.File] = append(byFile[h.File], h)
+	}
+
+	var b strings.Builder
+	for _, file := range order {
+		fileHunks := byFile[file]
+		sort.SliceStable(fileHunks, func(i, j int) bool {
+			return hunkOldStart(fileHunks[i].Body) < hunkOldStart(fileHunks[j].Body)
+		})
+
+		b.WriteString(fileHunks[0].Header)
+		for _, h := range fileHunks {
+			b.WriteString(h.Body)
+		}
+	}
+	return b.String()
+}
+
+// ApplyToIndex applies a patch to the index only, leaving the working tree
+// untouched
+func ApplyToIndex(patch string) error {
+	if patch == "" {
+		return nil
+	}
+
+	cmd := exec.Command("git", "apply", "--cached", "--whitespace=nowarn", "-")
+	cmd.Stdin = strings.NewReader(patch)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to stage patch: %w\n%s", err, stderr.String())
+	}
+	return nil
+}
+
+// ResetIndex unstages everything, leaving the working tree untouched
+func ResetIndex() error {
+	args := []string{"reset", "-q"}
+	if err := exec.Command("git", "rev-parse", "--verify", "-q", "HEAD").Run(); err != nil {
+		// No commits yet, there is nothing to reset to
+		args = []string{"read-tree", "--empty"}
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to reset index: %w\n%s", err, stderr.String())
+	}
+	return nil
+}
+
+// splitFiles splits a diff into one section per file
+func splitFiles(diff string) []string {
+	var sections []string
+	start := -1
+	offset := 0
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			if start >= 0 {
+				sections = append(sections, diff[start:offset])
+			}
+			start = offset
+		}
+		offset += len(line)
+	}
+	if start >= 0 {
+		sections = append(sections, diff[start:])
+	}
+	return sections
+}
+
+// hunkStart returns the offset of the first "@@" line at or after from
+func hunkStart(section string, from int) int {
+	for offset := from; offset < len(section); {
+		if (offset == 0 || section[offset-1] == '\n') && strings.HasPrefix(section[offset:], "@@ ") {
+			return offset
+		}
+		next := strings.IndexByte(section[offset:], '\n')
+		if next < 0 {
+			break
+		}
+		offset += next + 1
+	}
+	return -1
+}
+
+// hunkOldStart returns the first line of the original file a hunk body
+// covers, from its "@@ -start,count" line
+func hunkOldStart(body string) int {
+	rest, ok := strings.CutPrefix(body, "@@ -")
+	if !ok {
+		return 0
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end < 0 {
+		return 0
+	}
+	start, _ := strconv.Atoi(rest[:end])
+	return start
+}
+
+func splittable(header string) bool {
+	for _, marker := range []string{"\nnew file mode", "\ndeleted file mode", "\nold mode", "\nBinary files"} {
+		if strings.Contains(header, marker) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,221 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+// lines joins diff lines, each terminated by a newline like git's output
+func lines(ls ...string) string {
+	return strings.Join(ls, "\n") + "\n"
+}
+
+var (
+	modifiedHeader = lines(
+		"diff --git a/main.go b/main.go",
+		"index 1111111..2222222 100644",
+		"--- a/main.go",
+		"+++ b/main.go",
+	)
+	modifiedFirst = lines(
+		"@@ -1,3 +1,3 @@",
+		" package main",
+		"-var a = 1",
+		"+var a = 2",
+	)
+	modifiedSecond = lines(
+		"@@ -20,3 +20,4 @@ func main() {",
+		" \tfmt.Println(a)",
+		"+\tfmt.Println(b)",
+		" }",
+	)
+	modifiedThird = lines(
+		"@@ -100,2 +101,2 @@",
+		"-// old",
+		"+// new",
+	)
+	newFile = lines(
+		"diff --git a/new.go b/new.go",
+		"new file mode 100644",
+		"index 0000000..3333333",
+		"--- /dev/null",
+		"+++ b/new.go",
+		"@@ -0,0 +1,2 @@",
+		"+package main",
+		"+",
+		"@@ -0,0 +10,1 @@",
+		"+// not a real second hunk, kept with the first",
+	)
+	deletedFile = lines(
+		"diff --git a/old.go b/old.go",
+		"deleted file mode 100644",
+		"index 4444444..0000000",
+		"--- a/old.go",
+		"+++ /dev/null",
+		"@@ -1,2 +0,0 @@",
+		"-package main",
+		"-",
+	)
+	binaryFile = lines(
+		"diff --git a/logo.png b/logo.png",
+		"index 5555555..6666666 100644",
+		"Binary files a/logo.png and b/logo.png differ",
+	)
+	modeChange = lines(
+		"diff --git a/run.sh b/run.sh",
+		"old mode 100644",
+		"new mode 100755",
+		"index 7777777..8888888",
+		"--- a/run.sh",
+		"+++ b/run.sh",
+		"@@ -1 +1 @@",
+		"-echo a",
+		"+echo b",
+	)
+	dashedLines = lines(
+		"diff --git a/schema.sql b/schema.sql",
+		"index 9999999..aaaaaaa 100644",
+		"--- a/schema.sql",
+		"+++ b/schema.sql",
+		"@@ -1,2 +1,2 @@",
+		"-- old comment",
+		"+++ looks like a header",
+		"@@ -9 +9 @@",
+		"-x",
+		"+y",
+	)
+)
+
+func TestParseHunks(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want []Hunk
+	}{
+		{
+			name: "empty diff",
+			diff: "",
+		},
+		{
+			name: "modified file is split per hunk",
+			diff: modifiedHeader + modifiedFirst + modifiedSecond,
+			want: []Hunk{
+				{File: "main.go", Header: modifiedHeader, Body: modifiedFirst},
+				{File: "main.go", Header: modifiedHeader, Body: modifiedSecond},
+			},
+		},
+		{
+			name: "new file is one hunk",
+			diff: newFile,
+			want: []Hunk{{File: "new.go", Header: newFile}},
+		},
+		{
+			name: "deleted file is one hunk",
+			diff: deletedFile,
+			want: []Hunk{{File: "old.go", Header: deletedFile}},
+		},
+		{
+			name: "binary file is one hunk",
+			diff: binaryFile,
+			want: []Hunk{{File: "logo.png", Header: binaryFile}},
+		},
+		{
+			name: "mode change is one hunk",
+			diff: modeChange,
+			want: []Hunk{{File: "run.sh", Header: modeChange}},
+		},
+		{
+			name: "lines that look like headers stay in their hunk",
+			diff: dashedLines,
+			want: []Hunk{
+				{File: "schema.sql", Header: dashedLines[:strings.Index(dashedLines, "@@")], Body: lines("@@ -1,2 +1,2 @@", "-- old comment", "+++ looks like a header")},
+				{File: "schema.sql", Header: dashedLines[:strings.Index(dashedLines, "@@")], Body: lines("@@ -9 +9 @@", "-x", "+y")},
+			},
+		},
+		{
+			name: "several files",
+			diff: newFile + modifiedHeader + modifiedFirst + deletedFile,
+			want: []Hunk{
+				{File: "new.go", Header: newFile},
+				{File: "main.go", Header: modifiedHeader, Body: modifiedFirst},
+				{File: "old.go", Header: deletedFile},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseHunks(tt.diff)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d hunks, want %d: %#v", len(got), len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("hunk %d = %#v, want %#v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuildPatch(t *testing.T) {
+	parsed := ParseHunks(newFile + modifiedHeader + modifiedFirst + modifiedSecond + modifiedThird + deletedFile)
+	newHunk, first, second, third, deleted := parsed[0], parsed[1], parsed[2], parsed[3], parsed[4]
+
+	tests := []struct {
+		name  string
+		hunks []Hunk
+		want  string
+	}{
+		{
+			name: "no hunks",
+		},
+		{
+			name:  "round trip",
+			hunks: parsed,
+			want:  newFile + modifiedHeader + modifiedFirst + modifiedSecond + modifiedThird + deletedFile,
+		},
+		{
+			name:  "one header per file",
+			hunks: []Hunk{first, third},
+			want:  modifiedHeader + modifiedFirst + modifiedThird,
+		},
+		{
+			name:  "out of order hunks are sorted",
+			hunks: []Hunk{third, first, second},
+			want:  modifiedHeader + modifiedFirst + modifiedSecond + modifiedThird,
+		},
+		{
+			name:  "files keep the order they first appear in",
+			hunks: []Hunk{deleted, second, newHunk, first},
+			want:  deletedFile + modifiedHeader + modifiedFirst + modifiedSecond + newFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildPatch(tt.hunks); got != tt.want {
+				t.Errorf("BuildPatch() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHunkOldStart(t *testing.T) {
+	tests := []struct {
+		body string
+		want int
+	}{
+		{"@@ -1,3 +1,3 @@\n", 1},
+		{"@@ -20,3 +20,4 @@ func main() {\n", 20},
+		{"@@ -9 +9 @@\n", 9},
+		{"@@ -0,0 +1,2 @@\n", 0},
+		{"", 0},
+	}
+
+	for _, tt := range tests {
+		if got := hunkOldStart(tt.body); got != tt.want {
+			t.Errorf("hunkOldStart(%q) = %d, want %d", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// PathMatcher matches repository paths against gitignore-style patterns
+type PathMatcher struct {
+	rules []pathRule
+}
+
+type pathRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+// NewPathMatcher compiles gitignore-style patterns. Patterns without a
+// slash match at any depth, a leading slash anchors to the repository
+// root, a trailing slash matches directories only, "**" matches across
+// directories and "!" re-includes a previously matched path. Later
+// patterns win, as in .gitignore.
+func NewPathMatcher(patterns []string) (*PathMatcher, error) {
+	m := &PathMatcher{}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+
+		rule := pathRule{}
+		if strings.HasPrefix(p, "!") {
+			rule.negate = true
+			p = p[1:]
+		}
+
+		re, err := patternToRegexp(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern %q: %w", p, err)
+		}
+		rule.pattern = re
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// Match reports whether the path is matched by the patterns
+func (m *PathMatcher) Match(path string) bool {
+	if m == nil {
+		return false
+	}
+
+	matched := false
+	for _, rule := range m.rules {
+		if rule.pattern.MatchString(path) {
+			matched = !rule.negate
+		}
+	}
+	return matched
+}
+
+func patternToRegexp(p string) (*regexp.Regexp, error) {
+	dirOnly := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; {
+		case strings.HasPrefix(p[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(p[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if dirOnly {
+		b.WriteString("/.*$")
+	} else {
+		b.WriteString("(?:/.*)?$")
+	}
+
+	return regexp.Compile(b.String())
+}
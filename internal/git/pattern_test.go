@@ -0,0 +1,66 @@
+package git
+
+import "testing"
+
+func TestPathMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{"no patterns", nil, "main.go", false},
+		{"comments and blanks are ignored", []string{"# *.go", "  "}, "main.go", false},
+
+		{"extension at the root", []string{"*.lock"}, "yarn.lock", true},
+		{"extension at any depth", []string{"*.lock"}, "web/app/yarn.lock", true},
+		{"star doesn't cross directories", []string{"web/*.lock"}, "web/app/yarn.lock", false},
+		{"star within a directory", []string{"web/*.lock"}, "web/yarn.lock", true},
+		{"question mark", []string{"file?.txt"}, "file1.txt", true},
+		{"question mark needs one character", []string{"file?.txt"}, "file.txt", false},
+		{"dots are literal", []string{"*.min.js"}, "appxminxjs", false},
+
+		{"name matches a directory at any depth", []string{"vendor"}, "a/vendor/lib.go", true},
+		{"name matches a file", []string{"vendor"}, "vendor", true},
+		{"name is not a prefix", []string{"vendor"}, "vendored/lib.go", false},
+		{"trailing slash matches directories", []string{"dist/"}, "web/dist/app.js", true},
+		{"trailing slash doesn't match files", []string{"dist/"}, "dist", false},
+
+		{"leading slash anchors", []string{"/build"}, "build/out.o", true},
+		{"leading slash doesn't match deeper", []string{"/build"}, "src/build/out.o", false},
+		{"inner slash anchors", []string{"docs/api"}, "docs/api/index.md", true},
+		{"inner slash doesn't match deeper", []string{"docs/api"}, "src/docs/api/index.md", false},
+
+		{"leading double star", []string{"**/testdata"}, "internal/git/testdata/a.diff", true},
+		{"leading double star at the root", []string{"**/testdata"}, "testdata/a.diff", true},
+		{"double star in the middle", []string{"api/**/gen.go"}, "api/v1/types/gen.go", true},
+		{"double star matches no directories", []string{"api/**/gen.go"}, "api/gen.go", true},
+		{"double star in the middle is anchored", []string{"api/**/gen.go"}, "x/api/v1/gen.go", false},
+		{"trailing double star", []string{"generated/**"}, "generated/a/b.go", true},
+		{"trailing double star needs content", []string{"generated/**"}, "generated", false},
+		{"double star with extension", []string{"**/*.pb.go"}, "proto/v1/user.pb.go", true},
+
+		{"negation re-includes", []string{"*.lock", "!Cargo.lock"}, "Cargo.lock", false},
+		{"negation only affects matches", []string{"*.lock", "!Cargo.lock"}, "yarn.lock", true},
+		{"later patterns win", []string{"!Cargo.lock", "*.lock"}, "Cargo.lock", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewPathMatcher(tt.patterns)
+			if err != nil {
+				t.Fatalf("NewPathMatcher: %v", err)
+			}
+			if got := m.Match(tt.path); got != tt.want {
+				t.Errorf("Match(%q) with %q = %v, want %v", tt.path, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathMatcherNil(t *testing.T) {
+	var m *PathMatcher
+	if m.Match("main.go") {
+		t.Error("nil matcher matched a path")
+	}
+}
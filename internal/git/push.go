@@ -0,0 +1,122 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GetUpstream returns the upstream of the current branch, such as
+// "origin/main", or an empty string when there is none
+func GetUpstream() string {
+	output, err := exec.Command("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// GetDefaultRemote returns the remote a branch should be pushed to: its
+// configured remote, then "origin", then the only remote there is
+func GetDefaultRemote(branch string) (string, error) {
+	if output, err := exec.Command("git", "config", "branch."+branch+".remote").Output(); err == nil {
+		return strings.TrimSpace(string(output)), nil
+	}
+
+	output, err := exec.Command("git", "remote").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list remotes: %w", err)
+	}
+
+	remotes := strings.Fields(string(output))
+	for _, remote := range remotes {
+		if remote == "origin" {
+			return remote, nil
+		}
+	}
+	if len(remotes) == 1 {
+		return remotes[0], nil
+	}
+	if len(remotes) == 0 {
+		return "", fmt.Errorf("no remote configured")
+	}
+	return "", fmt.Errorf("several remotes configured, set the upstream with git push -u")
+}
+
+// GetShortStat returns the --shortstat summary of the changes between two
+// revisions, like "2 files changed, 10 insertions(+), 3 deletions(-)"
+func GetShortStat(from, to string) (string, error) {
+	output, err := exec.Command("git", "diff", "--shortstat", from, to).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff stats: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Push pushes the current branch to its upstream. With setUpstream, the
+// branch is pushed to remote instead and tracked from then on. Output is
+// passed to onLine as it arrives; replace is set for progress updates
+// that overwrite the previous line.
+func Push(setUpstream bool, remote, branch string, onLine func(line string, replace bool)) error {
+	args := []string{"push", "--progress"}
+	if setUpstream {
+		args = append(args, "--set-upstream", remote, branch)
+	}
+
+	// The TUI owns the terminal, so credential and passphrase prompts
+	// would hang it. Fail instead and explain how to push without them.
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if sshCommand := batchSSHCommand(); sshCommand != "" {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND="+sshCommand)
+	}
+
+	output, err := streamCommand(cmd, onLine)
+	if err != nil {
+		if needsCredentials(output) {
+			return fmt.Errorf("failed to push: %w: the remote asked for credentials, which can't be entered here. "+
+				"Set up a credential helper or ssh-agent, or push from a shell", err)
+		}
+		return fmt.Errorf("failed to push: %w", err)
+	}
+	return nil
+}
+
+// batchSSHCommand returns the ssh command git would use with BatchMode
+// enabled, so ssh fails instead of asking for a passphrase or password.
+// It is empty when a GIT_SSH program is configured, which can't take ssh
+// options.
+func batchSSHCommand() string {
+	if os.Getenv("GIT_SSH") != "" && os.Getenv("GIT_SSH_COMMAND") == "" {
+		return ""
+	}
+
+	command := os.Getenv("GIT_SSH_COMMAND")
+	if command == "" {
+		if output, err := exec.Command("git", "config", "core.sshCommand").Output(); err == nil {
+			command = strings.TrimSpace(string(output))
+		}
+	}
+	if command == "" {
+		command = "ssh"
+	}
+	return command + " -o BatchMode=yes"
+}
+
+// needsCredentials reports whether push output shows a failed prompt for
+// credentials
+func needsCredentials(output string) bool {
+	for _, marker := range []string{
+		"terminal prompts disabled",
+		"could not read Username",
+		"could not read Password",
+		"Permission denied (publickey",
+		"Host key verification failed",
+	} {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
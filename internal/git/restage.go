@@ -0,0 +1,105 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// RestageChanges stages only what changed in the working tree since the
+// before snapshot from HashWorktreeFiles, such as a formatter hook's edits.
+// The changes are merged into the staged version of each file, so partial
+// staging done before is kept. Nothing is staged if any file can't be
+// merged cleanly.
+func RestageChanges(files []string, before map[string]string) error {
+	type entry struct {
+		mode, blob, file string
+	}
+
+	var entries []entry
+	for _, file := range files {
+		base, ok := before[file]
+		if !ok {
+			return fmt.Errorf("failed to re-stage %s: it didn't exist before the commit", file)
+		}
+		if _, err := os.Lstat(file); err != nil {
+			return fmt.Errorf("failed to re-stage %s: it was deleted", file)
+		}
+
+		mode, staged, err := indexEntry(file)
+		if err != nil {
+			return err
+		}
+
+		merged, err := mergeBlobs(file, staged, base)
+		if err != nil {
+			return err
+		}
+
+		cmd := exec.Command("git", "hash-object", "-w", "--stdin", "--path", file)
+		cmd.Stdin = bytes.NewReader(merged)
+		output, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("failed to re-stage %s: %w", file, err)
+		}
+		entries = append(entries, entry{mode: mode, blob: strings.TrimSpace(string(output)), file: file})
+	}
+
+	for _, e := range entries {
+		cacheinfo := e.mode + "," + e.blob + "," + e.file
+		if output, err := exec.Command("git", "update-index", "--cacheinfo", cacheinfo).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to re-stage %s: %w\n%s", e.file, err, output)
+		}
+	}
+	return nil
+}
+
+// indexEntry returns the mode and blob of a file's staged version
+func indexEntry(file string) (string, string, error) {
+	output, err := exec.Command("git", "ls-files", "-s", "--", file).Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read the index entry of %s: %w", file, err)
+	}
+
+	// "<mode> <blob> <stage>\t<path>"
+	fields := strings.Fields(string(output))
+	if len(fields) < 3 {
+		return "", "", fmt.Errorf("failed to re-stage %s: it isn't staged", file)
+	}
+	return fields[0], fields[1], nil
+}
+
+// mergeBlobs applies the changes between the base blob and the file in the
+// working tree to the staged blob with a three-way merge
+func mergeBlobs(file, staged, base string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "anc-restage-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	current := dir + "/staged"
+	original := dir + "/base"
+	for path, blob := range map[string]string{current: staged, original: base} {
+		content, err := exec.Command("git", "cat-file", "blob", blob).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			return nil, err
+		}
+	}
+
+	merged, err := exec.Command("git", "merge-file", "-p", "-q", current, original, file).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return nil, fmt.Errorf("failed to re-stage %s: the hook's changes conflict with unstaged changes, stage it by hand", file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge %s: %w", file, err)
+	}
+	return merged, nil
+}
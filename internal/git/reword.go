@@ -0,0 +1,124 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ResolveCommit returns the full hash of a revision that names a commit
+func ResolveCommit(rev string) (string, error) {
+	output, err := exec.Command("git", "rev-parse", "--verify", "-q", rev+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("%s is not a commit", rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetCommitMessage returns the message of a commit
+func GetCommitMessage(rev string) (string, error) {
+	output, err := exec.Command("git", "log", "-1", "--format=%B", rev, "--").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get message of %s: %w", rev, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// IsAncestorOfHead reports whether a commit is reachable from HEAD
+func IsAncestorOfHead(rev string) (bool, error) {
+	err := exec.Command("git", "merge-base", "--is-ancestor", rev, "HEAD").Run()
+	if err == nil {
+		return true, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to compare %s with HEAD: %w", rev, err)
+}
+
+// HasUncommittedChanges reports whether tracked files have staged or
+// unstaged changes
+func HasUncommittedChanges() (bool, error) {
+	output, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to get status: %w", err)
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// Reword replaces the message of a commit reachable from HEAD. A copy of
+// the commit with the new message is created with commit-tree, keeping
+// its tree, parents and author, and the commits after it are replayed on
+// top with a non-interactive rebase. If the rebase fails it is aborted,
+// which leaves the branch where it was. The signing options apply to the
+// copy and to the replayed commits; commit-tree runs no hooks.
+func Reword(rev, message string, opts CommitOptions) error {
+	commit, err := ResolveCommit(rev)
+	if err != nil {
+		return err
+	}
+
+	output, err := exec.Command("git", "log", "-1", "--format=%P%x00%an%x00%ae%x00%ad", "--date=raw", commit).Output()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", rev, err)
+	}
+	fields := strings.SplitN(strings.TrimRight(string(output), "\n"), "\x00", 4)
+	if len(fields) != 4 {
+		return fmt.Errorf("failed to read %s: unexpected log output", rev)
+	}
+
+	args := append([]string{"commit-tree", commit + "^{tree}", "-m", message}, opts.signArgs()...)
+	for _, parent := range strings.Fields(fields[0]) {
+		args = append(args, "-p", parent)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME="+fields[1],
+		"GIT_AUTHOR_EMAIL="+fields[2],
+		"GIT_AUTHOR_DATE="+fields[3],
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err = cmd.Output()
+	if err != nil {
+		if signErr := signingError(stderr.String()); signErr != nil {
+			return signErr
+		}
+		return fmt.Errorf("failed to create reworded commit: %w\n%s", err, stderr.String())
+	}
+	reworded := strings.TrimSpace(string(output))
+
+	// Replay commit..HEAD onto the reworded copy. The trees are identical,
+	// so this only conflicts when merges have to be recreated.
+	rebaseArgs := append([]string{"rebase", "--rebase-merges"}, opts.signArgs()...)
+	rebase := exec.Command("git", append(rebaseArgs, "--onto", reworded, commit)...)
+	rebase.Env = append(os.Environ(), "GIT_EDITOR=true")
+	var rebaseOut bytes.Buffer
+	rebase.Stdout = &rebaseOut
+	rebase.Stderr = &rebaseOut
+	if err := rebase.Run(); err != nil {
+		if rebaseInProgress() {
+			if abortErr := exec.Command("git", "rebase", "--abort").Run(); abortErr != nil {
+				return fmt.Errorf("rebase failed and could not be aborted, run 'git rebase --abort': %w\n%s", err, rebaseOut.String())
+			}
+		}
+		if signErr := signingError(rebaseOut.String()); signErr != nil {
+			return signErr
+		}
+		return fmt.Errorf("rebase failed, nothing was changed: %w\n%s", err, rebaseOut.String())
+	}
+
+	return nil
+}
+
+func rebaseInProgress() bool {
+	output, err := exec.Command("git", "rev-parse", "--git-path", "rebase-merge").Output()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(strings.TrimSpace(string(output)))
+	return err == nil
+}
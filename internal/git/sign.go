@@ -0,0 +1,85 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Signature formats of gpg.format
+const (
+	SignFormatOpenPGP = "openpgp"
+	SignFormatSSH     = "ssh"
+	SignFormatX509    = "x509"
+)
+
+// ErrSigning is returned when a commit couldn't be signed
+var ErrSigning = errors.New("failed to sign commit")
+
+// SigningConfig is the signing setup from git config
+type SigningConfig struct {
+	Enabled bool   // commit.gpgSign
+	Format  string // gpg.format, openpgp when unset
+	Key     string // user.signingKey
+}
+
+// GetSigningConfig reads the signing settings from git config
+func GetSigningConfig() SigningConfig {
+	cfg := SigningConfig{Format: SignFormatOpenPGP}
+
+	if output, err := exec.Command("git", "config", "--type=bool", "commit.gpgSign").Output(); err == nil {
+		cfg.Enabled = strings.TrimSpace(string(output)) == "true"
+	}
+	if output, err := exec.Command("git", "config", "gpg.format").Output(); err == nil {
+		cfg.Format = strings.TrimSpace(string(output))
+	}
+	if output, err := exec.Command("git", "config", "user.signingKey").Output(); err == nil {
+		cfg.Key = strings.TrimSpace(string(output))
+	}
+
+	return cfg
+}
+
+// signArgs returns the signing flags for git commit, commit-tree and rebase
+func (o CommitOptions) signArgs() []string {
+	switch {
+	case o.Sign && o.SigningKey != "":
+		return []string{"--gpg-sign=" + o.SigningKey}
+	case o.Sign:
+		return []string{"--gpg-sign"}
+	case o.NoSign:
+		return []string{"--no-gpg-sign"}
+	}
+	return nil
+}
+
+// signingError turns git's output for a failed signature into an error
+// that says how to fix it, or returns nil when signing wasn't the problem
+func signingError(stderr string) error {
+	lower := strings.ToLower(stderr)
+
+	switch {
+	case strings.Contains(lower, "gpg failed to sign"),
+		strings.Contains(lower, "inappropriate ioctl"),
+		strings.Contains(lower, "pinentry"):
+		return fmt.Errorf("%w: gpg could not sign the commit. If no passphrase prompt appeared, "+
+			"set GPG_TTY=$(tty) in your shell profile or configure a graphical pinentry, "+
+			"unlock the key with 'echo test | gpg --clearsign', and check user.signingKey matches "+
+			"'gpg --list-secret-keys'\n%s", ErrSigning, strings.TrimSpace(stderr))
+
+	case strings.Contains(lower, "ssh-keygen"),
+		strings.Contains(lower, "user.signingkey"),
+		strings.Contains(lower, "gpg.ssh"),
+		strings.Contains(lower, "load key"):
+		return fmt.Errorf("%w: ssh signing failed. Check that user.signingKey is the path to your key "+
+			"(or its .pub file) or a 'key::' literal, and that the key is loaded with 'ssh-add'\n%s",
+			ErrSigning, strings.TrimSpace(stderr))
+
+	case strings.Contains(lower, "gpgsm"):
+		return fmt.Errorf("%w: x509 signing failed. Check that gpgsm has the certificate for user.signingKey\n%s",
+			ErrSigning, strings.TrimSpace(stderr))
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// maxStreamLine is the longest output line passed on whole. Longer lines
+// end the line by line streaming, the rest of the output is still read.
+const maxStreamLine = 1024 * 1024
+
+// streamCommand runs a command with stdout and stderr merged, passing each
+// line to onLine as it arrives, and returns the whole output. replace is
+// set for progress updates that overwrite the previous line.
+func streamCommand(cmd *exec.Cmd, onLine func(line string, replace bool)) (string, error) {
+	pipe, err := cmd.StderrPipe()
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdout = cmd.Stderr
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	var output strings.Builder
+	scanner := bufio.NewScanner(pipe)
+	scanner.Buffer(make([]byte, 64*1024), maxStreamLine)
+	scanner.Split(scanProgressLines)
+	replace := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		if onLine != nil {
+			onLine(strings.TrimSuffix(line, "\r"), replace)
+		}
+		if !strings.HasSuffix(line, "\r") {
+			output.WriteString(line + "\n")
+		}
+		replace = strings.HasSuffix(line, "\r")
+	}
+
+	// After a scan error, keep reading so the command can't block on a
+	// full pipe and Wait returns
+	if scanner.Err() != nil {
+		rest, _ := io.ReadAll(pipe)
+		output.Write(rest)
+		if onLine != nil && len(rest) > 0 {
+			onLine("[output too long to show]", false)
+		}
+	}
+
+	return output.String(), cmd.Wait()
+}
+
+// scanProgressLines splits output on "\n" and on the "\r" git uses to
+// redraw progress, keeping a trailing "\r" so callers can tell them apart
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// Wait for the next byte to tell "\r" from "\r\n"
+		if i+1 == len(data) && !atEOF {
+			return 0, nil, nil
+		}
+		if i+1 < len(data) && data[i+1] == '\n' {
+			return i + 2, data[:i], nil
+		}
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,64 @@
+package git
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStreamCommandLongLine(t *testing.T) {
+	// A line over maxStreamLine followed by enough output to fill the pipe
+	script := "head -c 2000000 /dev/zero | tr '\\0' a; echo; seq 1 100000; echo done"
+
+	var lines []string
+	done := make(chan struct{})
+	var output string
+	var err error
+	go func() {
+		output, err = streamCommand(exec.Command("sh", "-c", script), func(line string, replace bool) {
+			lines = append(lines, line)
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("streamCommand didn't return")
+	}
+
+	if err != nil {
+		t.Fatalf("streamCommand: %v", err)
+	}
+	if !strings.HasSuffix(output, "done\n") {
+		t.Errorf("output ends with %q, want the rest of the output", output[len(output)-20:])
+	}
+	if len(lines) != 1 || lines[0] != "[output too long to show]" {
+		t.Errorf("lines = %q, want a single note", lines)
+	}
+}
+
+func TestScanProgressLines(t *testing.T) {
+	var lines []string
+	var replaced []bool
+	_, err := streamCommand(exec.Command("printf", `one\ntwo 50%%\rtwo 100%%\r\nthree`), func(line string, replace bool) {
+		lines = append(lines, line)
+		replaced = append(replaced, replace)
+	})
+	if err != nil {
+		t.Fatalf("streamCommand: %v", err)
+	}
+
+	want := []string{"one", "two 50%", "two 100%", "three"}
+	wantReplaced := []bool{false, false, true, false}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	for i := range wantReplaced {
+		if i < len(replaced) && replaced[i] != wantReplaced[i] {
+			t.Errorf("replace = %v, want %v", replaced, wantReplaced)
+			break
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// GetCommentChar returns the prefix of comment lines in commit messages,
+// from core.commentString or core.commentChar. "auto" and unset both
+// give "#", the character git starts with.
+func GetCommentChar() string {
+	for _, key := range []string{"core.commentString", "core.commentChar"} {
+		output, err := exec.Command("git", "config", key).Output()
+		if err != nil {
+			continue
+		}
+		if value := strings.TrimSpace(string(output)); value != "" && value != "auto" {
+			return value
+		}
+	}
+	return "#"
+}
+
+// GetCommitTemplate returns the contents of the commit.template file, or
+// an empty string when none is configured
+func GetCommitTemplate() (string, error) {
+	output, err := exec.Command("git", "config", "--path", "commit.template").Output()
+	if err != nil {
+		return "", nil
+	}
+	path := strings.TrimSpace(string(output))
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit template: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,232 @@
+package message
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultTypes are the Conventional Commits types accepted by default
+var DefaultTypes = []string{
+	"feat", "fix", "refactor", "perf", "docs", "test",
+	"build", "ci", "style", "chore", "revert",
+}
+
+// Header is a parsed Conventional Commits subject line
+type Header struct {
+	Type        string
+	Scope       string
+	Breaking    bool
+	Description string
+}
+
+var headerPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+
+// ParseHeader parses a subject line of the form type(scope)!: description
+func ParseHeader(subject string) (Header, bool) {
+	match := headerPattern.FindStringSubmatch(subject)
+	if match == nil {
+		return Header{}, false
+	}
+
+	return Header{
+		Type:        match[1],
+		Scope:       match[2],
+		Breaking:    match[3] == "!",
+		Description: match[4],
+	}, true
+}
+
+// String formats the header back into a subject line
+func (h Header) String() string {
+	s := h.Type
+	if h.Scope != "" {
+		s += "(" + h.Scope + ")"
+	}
+	if h.Breaking {
+		s += "!"
+	}
+	return s + ": " + h.Description
+}
+
+// ValidateConventional checks a commit message against the Conventional
+// Commits specification using the given list of allowed types
+func ValidateConventional(msg string, types []string) []Violation {
+	if len(types) == 0 {
+		types = DefaultTypes
+	}
+
+	subject := Subject(msg)
+	if subject == "" {
+		return []Violation{{
+			Rule:     "conventional-header",
+			Message:  "commit message is empty",
+			Severity: SeverityError,
+		}}
+	}
+
+	header, ok := ParseHeader(subject)
+	if !ok {
+		return []Violation{{
+			Rule:     "conventional-header",
+			Message:  "subject must look like 'type(scope): description'",
+			Severity: SeverityError,
+		}}
+	}
+
+	var violations []Violation
+
+	if !contains(types, header.Type) {
+		violations = append(violations, Violation{
+			Rule:     "conventional-type",
+			Message:  fmt.Sprintf("type %q is not one of: %s", header.Type, strings.Join(types, ", ")),
+			Severity: SeverityError,
+		})
+	}
+
+	if header.Type != strings.ToLower(header.Type) {
+		violations = append(violations, Violation{
+			Rule:     "conventional-type-case",
+			Message:  "type must be lower-case",
+			Severity: SeverityError,
+		})
+	}
+
+	if strings.TrimSpace(header.Description) == "" {
+		violations = append(violations, Violation{
+			Rule:     "conventional-description",
+			Message:  "description must not be empty",
+			Severity: SeverityError,
+		})
+	}
+
+	if strings.Contains(subject, "():") || strings.Contains(subject, "()!:") {
+		violations = append(violations, Violation{
+			Rule:     "conventional-scope",
+			Message:  "scope must not be empty when parentheses are used",
+			Severity: SeverityError,
+		})
+	}
+
+	if header.Breaking && !strings.Contains(msg, "BREAKING CHANGE:") && !strings.Contains(msg, "BREAKING-CHANGE:") {
+		violations = append(violations, Violation{
+			Rule:     "conventional-breaking",
+			Message:  "breaking change marked with '!' should explain it in a BREAKING CHANGE: footer",
+			Severity: SeverityWarning,
+		})
+	}
+
+	return violations
+}
+
+// InferScope derives a scope for the changed paths from path prefix
+// mappings. The longest matching prefix wins for each path; a scope is only
+// returned when every mapped path agrees on it.
+func InferScope(paths []string, mappings map[string]string) string {
+	if len(mappings) == 0 {
+		return ""
+	}
+
+	prefixes := make([]string, 0, len(mappings))
+	for prefix := range mappings {
+		prefixes = append(prefixes, prefix)
+	}
+	// Longest prefix first so the most specific mapping is tried first
+	sort.Slice(prefixes, func(i, j int) bool {
+		return len(prefixes[i]) > len(prefixes[j])
+	})
+
+	scope := ""
+	for _, p := range paths {
+		for _, prefix := range prefixes {
+			if matchesPrefix(p, prefix) {
+				if scope != "" && scope != mappings[prefix] {
+					return ""
+				}
+				scope = mappings[prefix]
+				break
+			}
+		}
+	}
+
+	return scope
+}
+
+// InferType guesses a Conventional Commits type from the changed paths.
+// It only recognizes changes that are unambiguous from the paths alone and
+// returns an empty string otherwise, leaving the decision to the model.
+func InferType(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+
+	kind := ""
+	for _, p := range paths {
+		k := pathKind(p)
+		if k == "" || (kind != "" && k != kind) {
+			return ""
+		}
+		kind = k
+	}
+
+	return kind
+}
+
+// ApplyScope rewrites the scope of a conventional subject line, leaving the
+// rest of the message untouched. Messages that don't parse are returned as is.
+func ApplyScope(msg, scope string) string {
+	if scope == "" {
+		return msg
+	}
+
+	msg = strings.TrimLeft(msg, "\n")
+	subject := Subject(msg)
+	header, ok := ParseHeader(subject)
+	if !ok {
+		return msg
+	}
+
+	header.Scope = scope
+	return header.String() + msg[len(subject):]
+}
+
+func pathKind(p string) string {
+	base := path.Base(p)
+	ext := strings.ToLower(path.Ext(p))
+
+	switch {
+	case strings.HasPrefix(p, ".github/workflows/") || base == ".gitlab-ci.yml" || strings.HasPrefix(p, ".circleci/"):
+		return "ci"
+	case strings.HasSuffix(base, "_test.go") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec."):
+		return "test"
+	case ext == ".md" || ext == ".rst" || ext == ".adoc" || strings.HasPrefix(p, "docs/"):
+		return "docs"
+	case base == "go.mod" || base == "go.sum" || base == "Makefile" || base == "Dockerfile" ||
+		base == "package.json" || base == "package-lock.json" || base == ".goreleaser.yaml":
+		return "build"
+	}
+
+	return ""
+}
+
+func matchesPrefix(p, prefix string) bool {
+	prefix = strings.TrimPrefix(prefix, "./")
+	if prefix == "" {
+		return false
+	}
+	if strings.HasSuffix(prefix, "/") {
+		return strings.HasPrefix(p, prefix)
+	}
+	return p == prefix || strings.HasPrefix(p, prefix+"/")
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
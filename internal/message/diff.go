@@ -0,0 +1,90 @@
+package message
+
+import "unicode"
+
+// DiffOp says whether a part of a word diff is kept, removed or added
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffPart is a run of text with the same DiffOp
+type DiffPart struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffWords compares two messages word by word. Whitespace runs count as
+// words so the parts join back into either message.
+func DiffWords(a, b string) []DiffPart {
+	x, y := splitWords(a), splitWords(b)
+
+	// Longest common subsequence, commit messages are short enough for the
+	// quadratic table
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var parts []DiffPart
+	add := func(op DiffOp, text string) {
+		if n := len(parts); n > 0 && parts[n-1].Op == op {
+			parts[n-1].Text += text
+			return
+		}
+		parts = append(parts, DiffPart{Op: op, Text: text})
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			add(DiffEqual, x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(DiffDelete, x[i])
+			i++
+		default:
+			add(DiffInsert, y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		add(DiffDelete, x[i])
+	}
+	for ; j < len(y); j++ {
+		add(DiffInsert, y[j])
+	}
+	return parts
+}
+
+// splitWords splits text into alternating runs of whitespace and other
+// characters
+func splitWords(text string) []string {
+	var words []string
+	start, space := 0, false
+	for i, r := range text {
+		if i > start && unicode.IsSpace(r) != space {
+			words = append(words, text[start:i])
+			start = i
+		}
+		space = unicode.IsSpace(r)
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
@@ -0,0 +1,271 @@
+package message
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rules configures the commit message linter. Zero values disable a rule.
+type Rules struct {
+	Enabled               bool     `json:"enabled"`
+	Strict                bool     `json:"strict"`                   // Errors block the commit instead of warning
+	SubjectMaxLength      int      `json:"subject_max_length"`       // Warn when the subject is longer
+	SubjectHardLimit      int      `json:"subject_hard_limit"`       // Error when the subject is longer
+	ImperativeMood        bool     `json:"imperative_mood"`          // Subject starts with an imperative verb
+	NoTrailingPeriod      bool     `json:"no_trailing_period"`       // Subject doesn't end with a period
+	BlankLineAfterSubject bool     `json:"blank_line_after_subject"` // Body is separated from the subject
+	BodyWrapWidth         int      `json:"body_wrap_width"`          // Maximum body line length
+	ForbiddenWords        []string `json:"forbidden_words"`          // Case-insensitive whole words
+	RequiredTicket        string   `json:"required_ticket"`          // Regex a ticket reference must match
+	AutoFix               bool     `json:"auto_fix"`                 // Fix simple violations in generated messages
+}
+
+// DefaultRules returns the default linter rules
+func DefaultRules() Rules {
+	return Rules{
+		Enabled:               true,
+		SubjectMaxLength:      50,
+		SubjectHardLimit:      72,
+		ImperativeMood:        true,
+		NoTrailingPeriod:      true,
+		BlankLineAfterSubject: true,
+		BodyWrapWidth:         72,
+	}
+}
+
+// Lint checks a commit message against the rules
+func Lint(msg string, rules Rules) []Violation {
+	if !rules.Enabled {
+		return nil
+	}
+
+	lines := strings.Split(strings.Trim(msg, "\n"), "\n")
+	subject := strings.TrimRight(lines[0], " \t\r")
+	if subject == "" {
+		return []Violation{{
+			Rule:     "subject-empty",
+			Message:  "subject must not be empty",
+			Severity: SeverityError,
+		}}
+	}
+
+	var violations []Violation
+	add := func(rule string, severity Severity, format string, args ...interface{}) {
+		violations = append(violations, Violation{
+			Rule:     rule,
+			Message:  fmt.Sprintf(format, args...),
+			Severity: severity,
+		})
+	}
+
+	length := utf8.RuneCountInString(subject)
+	if rules.SubjectHardLimit > 0 && length > rules.SubjectHardLimit {
+		add("subject-length", SeverityError, "subject is %d characters, the limit is %d", length, rules.SubjectHardLimit)
+	} else if rules.SubjectMaxLength > 0 && length > rules.SubjectMaxLength {
+		add("subject-length", SeverityWarning, "subject is %d characters, aim for %d or fewer", length, rules.SubjectMaxLength)
+	}
+
+	if rules.NoTrailingPeriod && strings.HasSuffix(subject, ".") {
+		add("subject-period", SeverityWarning, "subject should not end with a period")
+	}
+
+	if rules.ImperativeMood {
+		if word := firstWord(subject); word != "" && !isImperative(word) {
+			add("subject-imperative", SeverityWarning, "subject should use the imperative mood (%q)", word)
+		}
+	}
+
+	if rules.BlankLineAfterSubject && len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		add("body-leading-blank", SeverityError, "separate the subject from the body with a blank line")
+	}
+
+	if rules.BodyWrapWidth > 0 {
+		for i, line := range lines[1:] {
+			if n := utf8.RuneCountInString(line); n > rules.BodyWrapWidth && !isUnwrappable(line) {
+				add("body-wrap", SeverityWarning, "line %d is %d characters, wrap the body at %d", i+2, n, rules.BodyWrapWidth)
+			}
+		}
+	}
+
+	for _, word := range rules.ForbiddenWords {
+		if word == "" {
+			continue
+		}
+		pattern := regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+		if pattern.MatchString(msg) {
+			add("forbidden-word", SeverityError, "message contains the forbidden word %q", word)
+		}
+	}
+
+	if rules.RequiredTicket != "" {
+		pattern, err := regexp.Compile(rules.RequiredTicket)
+		if err != nil {
+			add("ticket-reference", SeverityError, "invalid required_ticket pattern: %v", err)
+		} else if !pattern.MatchString(msg) {
+			add("ticket-reference", SeverityError, "message must reference a ticket matching %s", rules.RequiredTicket)
+		}
+	}
+
+	return violations
+}
+
+// Fix applies the simple, unambiguous fixes: trailing whitespace, the
+// trailing period on the subject, the blank line after the subject and
+// wrapping of long body paragraphs
+func Fix(msg string, rules Rules) string {
+	lines := strings.Split(strings.Trim(msg, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+
+	if rules.NoTrailingPeriod {
+		lines[0] = strings.TrimRight(lines[0], ".")
+	}
+
+	body := lines[1:]
+	if rules.BlankLineAfterSubject && len(body) > 0 && body[0] != "" {
+		body = append([]string{""}, body...)
+	}
+
+	if rules.BodyWrapWidth > 0 {
+		body = wrapLines(body, rules.BodyWrapWidth)
+	}
+
+	return strings.Join(append(lines[:1], body...), "\n")
+}
+
+// AsWarnings downgrades every violation to a warning
+func AsWarnings(violations []Violation) []Violation {
+	out := make([]Violation, len(violations))
+	for i, v := range violations {
+		v.Severity = SeverityWarning
+		out[i] = v
+	}
+	return out
+}
+
+// wrapLines re-flows paragraphs that have a line longer than width, joining
+// their lines before wrapping so a long line followed by a short one doesn't
+// come out ragged. Lines that can't be wrapped sensibly, such as trailers,
+// indented code and list items, end a paragraph and are left untouched.
+func wrapLines(lines []string, width int) []string {
+	var out, paragraph []string
+	flush := func() {
+		long := false
+		for _, line := range paragraph {
+			if utf8.RuneCountInString(line) > width {
+				long = true
+			}
+		}
+		if long {
+			out = append(out, wrapWords(strings.Fields(strings.Join(paragraph, " ")), width)...)
+		} else {
+			out = append(out, paragraph...)
+		}
+		paragraph = nil
+	}
+
+	for _, line := range lines {
+		if line == "" || isUnwrappable(line) {
+			flush()
+			out = append(out, line)
+			continue
+		}
+		paragraph = append(paragraph, line)
+	}
+	flush()
+	return out
+}
+
+// wrapWords fills lines of at most width with the words. Words longer than
+// width get a line of their own.
+func wrapWords(words []string, width int) []string {
+	var out []string
+	current := ""
+	for _, word := range words {
+		if current != "" && utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width {
+			out = append(out, current)
+			current = word
+			continue
+		}
+		if current == "" {
+			current = word
+		} else {
+			current += " " + word
+		}
+	}
+	if current != "" {
+		out = append(out, current)
+	}
+	return out
+}
+
+var trailerLine = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: `)
+
+func isUnwrappable(line string) bool {
+	return strings.HasPrefix(line, " ") ||
+		strings.HasPrefix(line, "\t") ||
+		strings.HasPrefix(line, "- ") ||
+		strings.HasPrefix(line, "* ") ||
+		trailerLine.MatchString(line) ||
+		!strings.Contains(strings.TrimSpace(line), " ")
+}
+
+// firstWord returns the first word of the subject description, skipping a
+// Conventional Commits header and ticket prefixes like "PROJ-12:"
+func firstWord(subject string) string {
+	if header, ok := ParseHeader(subject); ok {
+		subject = header.Description
+	}
+
+	for _, word := range strings.Fields(subject) {
+		if strings.HasSuffix(word, ":") || strings.HasPrefix(word, "[") || strings.HasPrefix(word, "#") {
+			continue
+		}
+		return strings.ToLower(strings.Trim(word, ".,;:!"))
+	}
+	return ""
+}
+
+var imperativeExceptions = map[string]bool{
+	"embed": true, "speed": true, "proceed": true, "succeed": true,
+	"exceed": true, "bring": true, "string": true, "bump": true,
+	"feed": true, "need": true, "seed": true, "bleed": true, "breed": true,
+	"shred": true,
+}
+
+var commonVerbs = []string{
+	"add", "fix", "updat", "remov", "chang", "refactor", "implement", "improv",
+	"creat", "delet", "renam", "mov", "clean", "support", "handl", "us", "mak",
+	"allow", "introduc", "replac", "document", "merg", "revert", "test", "drop",
+	"enabl", "disabl", "prevent", "ensur", "simplif", "extract", "optimiz",
+	"correct", "adjust", "upgrad", "initializ", "configur", "tweak", "bump",
+}
+
+// isImperative is a heuristic: it flags past tense ("added"), gerunds
+// ("adding") and third person ("adds") forms of common verbs
+func isImperative(word string) bool {
+	if imperativeExceptions[word] {
+		return true
+	}
+
+	for _, verb := range commonVerbs {
+		if !strings.HasPrefix(word, verb) {
+			continue
+		}
+		switch strings.TrimPrefix(word, verb) {
+		case "ed", "d", "ing", "s", "es", "ies", "ied":
+			return false
+		}
+	}
+
+	for _, suffix := range []string{"ed", "ing"} {
+		// A stem without a vowel, like "sw" in "swing", is part of the verb
+		if stem := strings.TrimSuffix(word, suffix); len(word) > 4 && stem != word && strings.ContainsAny(stem, "aeiouy") {
+			return false
+		}
+	}
+	return true
+}
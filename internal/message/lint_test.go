@@ -0,0 +1,259 @@
+package message
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	long := strings.Repeat("word ", 16) + "end" // 83 characters
+
+	tests := []struct {
+		name  string
+		msg   string
+		rules Rules
+		want  []Violation
+	}{
+		{
+			name:  "disabled",
+			msg:   "Added things.",
+			rules: Rules{},
+		},
+		{
+			name:  "clean message",
+			msg:   "Add login form\n\nThe form posts to the session endpoint.",
+			rules: DefaultRules(),
+		},
+		{
+			name:  "empty subject",
+			msg:   "\n\n",
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-empty", Severity: SeverityError}},
+		},
+		{
+			name:  "subject over the soft limit",
+			msg:   "Add " + strings.Repeat("x", 50),
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-length", Severity: SeverityWarning}},
+		},
+		{
+			name:  "subject over the hard limit",
+			msg:   "Add " + strings.Repeat("x", 70),
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-length", Severity: SeverityError}},
+		},
+		{
+			name:  "length counts characters, not bytes",
+			msg:   "Add " + strings.Repeat("é", 46),
+			rules: DefaultRules(),
+		},
+		{
+			name:  "trailing period",
+			msg:   "Add login form.",
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-period", Severity: SeverityWarning}},
+		},
+		{
+			name:  "past tense",
+			msg:   "Added login form",
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-imperative", Severity: SeverityWarning}},
+		},
+		{
+			name:  "gerund",
+			msg:   "Fixing the login form",
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-imperative", Severity: SeverityWarning}},
+		},
+		{
+			name:  "third person",
+			msg:   "Supports dark mode",
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-imperative", Severity: SeverityWarning}},
+		},
+		{
+			name:  "uncommon past tense",
+			msg:   "Dropped legacy flags",
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-imperative", Severity: SeverityWarning}},
+		},
+		{
+			name:  "verbs ending in ing",
+			msg:   "Swing the pendulum",
+			rules: DefaultRules(),
+		},
+		{
+			name:  "verbs ending in ed",
+			msg:   "Embed the fonts",
+			rules: DefaultRules(),
+		},
+		{
+			name:  "conventional header is skipped",
+			msg:   "feat(auth): added login form",
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "subject-imperative", Severity: SeverityWarning}},
+		},
+		{
+			name:  "ticket prefix is skipped",
+			msg:   "PROJ-12: Add login form",
+			rules: DefaultRules(),
+		},
+		{
+			name:  "missing blank line",
+			msg:   "Add login form\nThe form posts to the session endpoint.",
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "body-leading-blank", Severity: SeverityError}},
+		},
+		{
+			name:  "long body line",
+			msg:   "Add login form\n\n" + long,
+			rules: DefaultRules(),
+			want:  []Violation{{Rule: "body-wrap", Severity: SeverityWarning}},
+		},
+		{
+			name:  "unwrappable body lines",
+			msg:   "Add login form\n\n    " + long + "\nSee: https://example.com/" + strings.Repeat("x", 80),
+			rules: DefaultRules(),
+		},
+		{
+			name:  "forbidden word",
+			msg:   "Add login form\n\nWIP until the API lands.",
+			rules: Rules{Enabled: true, ForbiddenWords: []string{"wip"}},
+			want:  []Violation{{Rule: "forbidden-word", Severity: SeverityError}},
+		},
+		{
+			name:  "forbidden words match whole words",
+			msg:   "Add wipe command",
+			rules: Rules{Enabled: true, ForbiddenWords: []string{"wip"}},
+		},
+		{
+			name:  "ticket present",
+			msg:   "Add login form\n\nRefs: PROJ-12",
+			rules: Rules{Enabled: true, RequiredTicket: `[A-Z]+-\d+`},
+		},
+		{
+			name:  "ticket missing",
+			msg:   "Add login form",
+			rules: Rules{Enabled: true, RequiredTicket: `[A-Z]+-\d+`},
+			want:  []Violation{{Rule: "ticket-reference", Severity: SeverityError}},
+		},
+		{
+			name:  "invalid ticket pattern",
+			msg:   "Add login form",
+			rules: Rules{Enabled: true, RequiredTicket: `[`},
+			want:  []Violation{{Rule: "ticket-reference", Severity: SeverityError}},
+		},
+		{
+			name:  "several violations",
+			msg:   "Updated login form.\nWIP",
+			rules: Rules{Enabled: true, NoTrailingPeriod: true, ImperativeMood: true, BlankLineAfterSubject: true, ForbiddenWords: []string{"wip"}},
+			want: []Violation{
+				{Rule: "subject-period", Severity: SeverityWarning},
+				{Rule: "subject-imperative", Severity: SeverityWarning},
+				{Rule: "body-leading-blank", Severity: SeverityError},
+				{Rule: "forbidden-word", Severity: SeverityError},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Lint(tt.msg, tt.rules)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Lint() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i].Rule != tt.want[i].Rule || got[i].Severity != tt.want[i].Severity {
+					t.Errorf("violation %d = %s (%s), want %s (%s)", i, got[i].Rule, got[i].Severity, tt.want[i].Rule, tt.want[i].Severity)
+				}
+			}
+		})
+	}
+}
+
+func TestFix(t *testing.T) {
+	rules := DefaultRules()
+	rules.BodyWrapWidth = 20
+
+	tests := []struct {
+		name  string
+		msg   string
+		rules Rules
+		want  string
+	}{
+		{
+			name:  "clean message is unchanged",
+			msg:   "Add login form\n\nPosts to the API.",
+			rules: rules,
+			want:  "Add login form\n\nPosts to the API.",
+		},
+		{
+			name:  "trailing whitespace and blank lines",
+			msg:   "\nAdd login form  \n\nPosts to the API.\t\n\n",
+			rules: rules,
+			want:  "Add login form\n\nPosts to the API.",
+		},
+		{
+			name:  "trailing period",
+			msg:   "Add login form...",
+			rules: rules,
+			want:  "Add login form",
+		},
+		{
+			name:  "blank line after the subject",
+			msg:   "Add login form\nPosts to the API.",
+			rules: rules,
+			want:  "Add login form\n\nPosts to the API.",
+		},
+		{
+			name:  "long line is wrapped",
+			msg:   "Add login form\n\nThe form posts the credentials to the API.",
+			rules: rules,
+			want:  "Add login form\n\nThe form posts the\ncredentials to the\nAPI.",
+		},
+		{
+			name:  "paragraph is re-flowed",
+			msg:   "Add login form\n\nThe form posts the credentials\nto the API.",
+			rules: rules,
+			want:  "Add login form\n\nThe form posts the\ncredentials to the\nAPI.",
+		},
+		{
+			name:  "paragraphs stay separate",
+			msg:   "Add login form\n\nThe form posts the credentials.\n\nIt is short.",
+			rules: rules,
+			want:  "Add login form\n\nThe form posts the\ncredentials.\n\nIt is short.",
+		},
+		{
+			name:  "wrapped paragraphs are left alone",
+			msg:   "Add login form\n\nShort line\nand another",
+			rules: rules,
+			want:  "Add login form\n\nShort line\nand another",
+		},
+		{
+			name:  "lists, code and trailers are left alone",
+			msg:   "Add login form\n\n- a list item that is much too long\n    code that is also much too long\nSigned-off-by: A Person <a@example.com>",
+			rules: rules,
+			want:  "Add login form\n\n- a list item that is much too long\n    code that is also much too long\nSigned-off-by: A Person <a@example.com>",
+		},
+		{
+			name:  "long words get their own line",
+			msg:   "Add login form\n\nSee https://example.com/a/very/long/path now",
+			rules: rules,
+			want:  "Add login form\n\nSee\nhttps://example.com/a/very/long/path\nnow",
+		},
+		{
+			name:  "rules that are off don't apply",
+			msg:   "Add login form.\nThe form posts the credentials to the API.",
+			rules: Rules{},
+			want:  "Add login form.\nThe form posts the credentials to the API.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fix(tt.msg, tt.rules); got != tt.want {
+				t.Errorf("Fix() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
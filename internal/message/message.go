@@ -0,0 +1,44 @@
+package message
+
+import "strings"
+
+// Severity describes how serious a violation is
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// Violation is a single problem found in a commit message
+type Violation struct {
+	Rule     string
+	Message  string
+	Severity Severity
+}
+
+// HasErrors reports whether any of the violations is an error
+func HasErrors(violations []Violation) bool {
+	for _, v := range violations {
+		if v.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Subject returns the first line of a commit message
+func Subject(msg string) string {
+	msg = strings.TrimLeft(msg, "\n")
+	if i := strings.Index(msg, "\n"); i >= 0 {
+		return strings.TrimRight(msg[:i], " \t\r")
+	}
+	return strings.TrimRight(msg, " \t\r")
+}
@@ -0,0 +1,117 @@
+package message
+
+import "strings"
+
+// Scissors marks the start of text git ignores along with everything after
+// it, in a commit message opened in an editor
+const Scissors = "------------------------ >8 ------------------------"
+
+// commentChars are tried in order when the configured comment character
+// starts a line of the message, like git's core.commentChar=auto
+var commentChars = []string{"#", ";", "@", "!", "$", "%", "^", "&", "|", ":"}
+
+// CommentCharFor returns preferred if no line of msg starts with it, or
+// else the first of git's auto candidates that no line starts with, so
+// StripComments can't remove part of the message
+func CommentCharFor(msg, preferred string) string {
+	starts := func(char string) bool {
+		for _, line := range strings.Split(msg, "\n") {
+			if strings.HasPrefix(line, char) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !starts(preferred) {
+		return preferred
+	}
+	for _, char := range commentChars {
+		if !starts(char) {
+			return char
+		}
+	}
+	return preferred
+}
+
+// EditTemplate returns the contents of a commit message file for an
+// editor: the message, instructions and the diff, all commented out with
+// commentChar after a scissors line
+func EditTemplate(msg, diff, commentChar string) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(msg, "\n"))
+	b.WriteString("\n\n")
+
+	comment := func(line string) {
+		if line == "" {
+			b.WriteString(commentChar + "\n")
+			return
+		}
+		b.WriteString(commentChar + " " + line + "\n")
+	}
+	comment("Please enter the commit message for your changes. Lines starting")
+	comment("with '" + commentChar + "' will be ignored, and an empty message keeps the")
+	comment("previous one.")
+	comment(Scissors)
+	comment("Do not modify or remove the line above.")
+	comment("Everything below it will be ignored.")
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		comment(line)
+	}
+	return b.String()
+}
+
+// StripComments cleans up a message read back from an editor like git's
+// "strip" cleanup: everything from the scissors line on and lines starting
+// with commentChar are removed, trailing whitespace is trimmed and runs of
+// blank lines are collapsed
+func StripComments(text, commentChar string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == commentChar+" "+Scissors {
+			break
+		}
+		if strings.HasPrefix(line, commentChar) {
+			continue
+		}
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// StripTemplateComments removes the comment lines of a commit template
+// from a message. Only lines that appear in the template are removed, so
+// message lines that merely start with commentChar, such as a "#512: Fix
+// login" subject, are kept.
+func StripTemplateComments(msg, template, commentChar string) string {
+	comments := map[string]bool{}
+	for _, line := range strings.Split(template, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, commentChar) {
+			comments[line] = true
+		}
+	}
+	if len(comments) == 0 {
+		return msg
+	}
+
+	var lines []string
+	removed := false
+	for _, line := range strings.Split(msg, "\n") {
+		if comments[strings.TrimSpace(line)] {
+			removed = true
+			continue
+		}
+		// Don't leave a gap where a comment was between blank lines
+		if removed && line == "" && len(lines) > 0 && lines[len(lines)-1] == "" {
+			continue
+		}
+		removed = false
+		lines = append(lines, line)
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
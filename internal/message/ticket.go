@@ -0,0 +1,84 @@
+package message
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ticket placements
+const (
+	TicketPrefix  = "prefix"  // "PROJ-12: subject"
+	TicketTrailer = "trailer" // "Refs: PROJ-12" footer
+	TicketNone    = "none"    // Only mention the ticket in the prompt
+)
+
+// notTickets matches standard names that look like "PROJ-12" ticket IDs
+var notTickets = regexp.MustCompile(`^(?:UTF|UCS|ISO|SHA|MD|RFC|CVE|ES|TLS|HTTP)-[0-9]+$`)
+
+// ExtractTickets returns the distinct matches of pattern in s, in order.
+// Matches inside a longer word and names like UTF-8 or ISO-8859 are
+// skipped.
+func ExtractTickets(s, pattern string) ([]string, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var tickets []string
+	seen := map[string]bool{}
+	for _, loc := range re.FindAllStringIndex(s, -1) {
+		match := s[loc[0]:loc[1]]
+		if !wordBoundary(s, loc[0], loc[1]) || notTickets.MatchString(match) {
+			continue
+		}
+		if !seen[match] {
+			seen[match] = true
+			tickets = append(tickets, match)
+		}
+	}
+	return tickets, nil
+}
+
+// wordBoundary reports whether s[start:end] isn't preceded or followed by
+// a letter or digit
+func wordBoundary(s string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(s[:start]); start > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(s[end:]); end < len(s) && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+		return false
+	}
+	return true
+}
+
+// AddTicketPrefix prefixes the subject with the tickets it doesn't mention
+// yet. Conventional subjects keep their header and get the tickets at the
+// start of the description instead.
+func AddTicketPrefix(msg string, tickets []string) string {
+	msg = strings.TrimLeft(msg, "\n")
+	subject := Subject(msg)
+
+	var missing []string
+	for _, ticket := range tickets {
+		if !strings.Contains(subject, ticket) {
+			missing = append(missing, ticket)
+		}
+	}
+	if len(missing) == 0 {
+		return msg
+	}
+
+	prefix := strings.Join(missing, " ")
+	rest := msg[len(subject):]
+	if header, ok := ParseHeader(subject); ok {
+		header.Description = prefix + " " + header.Description
+		return header.String() + rest
+	}
+	return prefix + ": " + subject + rest
+}
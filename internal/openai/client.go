@@ -23,6 +23,22 @@ func NewClient(apiKey, model string, temperature float32) *Client {
 	}
 }
 
+// NewClientWithBaseURL creates a client for an OpenAI-compatible API at
+// baseURL. An empty baseURL uses the OpenAI API.
+func NewClientWithBaseURL(apiKey, baseURL, model string, temperature float32) *Client {
+	if baseURL == "" {
+		return NewClient(apiKey, model, temperature)
+	}
+
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.BaseURL = baseURL
+	return &Client{
+		client:      openai.NewClientWithConfig(clientConfig),
+		model:       model,
+		temperature: temperature,
+	}
+}
+
 // GenerateCommitMessage generates a commit message based on the diff
 func (c *Client) GenerateCommitMessage(systemPrompt, diff string) (string, error) {
 	if diff == "" {
@@ -0,0 +1,259 @@
+package scan
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Actions taken when secrets are found
+const (
+	ActionRedact = "redact" // Offer to redact the findings before sending
+	ActionBlock  = "block"  // Never send a diff with findings
+)
+
+// Rule is a named regular expression that matches a secret
+type Rule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// Config configures the secret scanner
+type Config struct {
+	Enabled          bool    `json:"enabled"`
+	Action           string  `json:"action"`            // "redact" or "block"
+	Rules            []Rule  `json:"rules"`             // Extra rules on top of the defaults
+	DisableDefaults  bool    `json:"disable_defaults"`  // Only use Rules
+	EntropyThreshold float64 `json:"entropy_threshold"` // Bits per character, 0 disables
+	EntropyMinLength int     `json:"entropy_min_length"`
+}
+
+// DefaultConfig returns the default scanner configuration
+func DefaultConfig() Config {
+	return Config{
+		Enabled:          true,
+		Action:           ActionRedact,
+		EntropyThreshold: 4.5,
+		EntropyMinLength: 24,
+	}
+}
+
+// DefaultRules match well-known credential formats
+var DefaultRules = []Rule{
+	{Name: "private-key", Pattern: `-----BEGIN [A-Z ]*PRIVATE KEY( BLOCK)?-----`},
+	{Name: "openai-key", Pattern: `sk-(?:proj-)?[A-Za-z0-9_-]{20,}`},
+	{Name: "aws-access-key", Pattern: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "github-token", Pattern: `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`},
+	{Name: "slack-token", Pattern: `\bxox[abposr]-[A-Za-z0-9-]{10,}\b`},
+	{Name: "google-api-key", Pattern: `\bAIza[0-9A-Za-z_-]{35}\b`},
+	{Name: "stripe-key", Pattern: `\b[rs]k_live_[0-9A-Za-z]{16,}\b`},
+	{Name: "jwt", Pattern: `\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`},
+	// Only quoted literals, so code like `Token: config.Token` passes
+	{Name: "credential-assignment", Pattern: `(?i)\b(?:api[_-]?key|secret|token|passw(?:or)?d)\b["']?\s*[:=]\s*(?:"[^\s"]{8,}"|'[^\s']{8,}')`},
+}
+
+// Finding is a secret found in a diff
+type Finding struct {
+	File  string
+	Line  int // Line number within the diff
+	Rule  string
+	Match string // Masked preview of the match
+}
+
+// Scanner finds and redacts secrets in unified diffs
+type Scanner struct {
+	rules            []compiledRule
+	entropyThreshold float64
+	entropyMinLength int
+}
+
+type compiledRule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_-]+`)
+var dotenvPattern = regexp.MustCompile(`^(\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_.]*\s*=\s*)(.+)$`)
+
+// New creates a scanner from the configuration
+func New(cfg Config) (*Scanner, error) {
+	rules := cfg.Rules
+	if !cfg.DisableDefaults {
+		rules = append(append([]Rule(nil), DefaultRules...), cfg.Rules...)
+	}
+
+	s := &Scanner{
+		entropyThreshold: cfg.EntropyThreshold,
+		entropyMinLength: cfg.EntropyMinLength,
+	}
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret scan rule %q: %w", rule.Name, err)
+		}
+		s.rules = append(s.rules, compiledRule{name: rule.Name, pattern: pattern})
+	}
+
+	return s, nil
+}
+
+// Scan returns the secrets found in a unified diff. Context and removed
+// lines are scanned as well, since they are sent along with the additions.
+func (s *Scanner) Scan(diff string) []Finding {
+	var findings []Finding
+	s.walk(diff, func(file string, lineNo int, content string) string {
+		for _, f := range s.scanLine(file, content) {
+			f.Line = lineNo
+			findings = append(findings, f)
+		}
+		return content
+	})
+	return findings
+}
+
+// Redact replaces every secret in a unified diff with a placeholder
+func (s *Scanner) Redact(diff string) string {
+	return s.walk(diff, func(file string, _ int, content string) string {
+		if isDotenv(file) {
+			if m := dotenvPattern.FindStringSubmatch(content); m != nil {
+				return m[1] + "[REDACTED:dotenv]"
+			}
+		}
+		for _, rule := range s.rules {
+			content = rule.pattern.ReplaceAllString(content, "[REDACTED:"+rule.name+"]")
+		}
+		for _, token := range s.highEntropyTokens(content) {
+			content = strings.ReplaceAll(content, token, "[REDACTED:high-entropy]")
+		}
+		return content
+	})
+}
+
+// Files returns the distinct files with findings, sorted
+func Files(findings []Finding) []string {
+	seen := map[string]bool{}
+	var files []string
+	for _, f := range findings {
+		if !seen[f.File] {
+			seen[f.File] = true
+			files = append(files, f.File)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func (s *Scanner) scanLine(file, content string) []Finding {
+	var findings []Finding
+
+	if isDotenv(file) {
+		if m := dotenvPattern.FindStringSubmatch(content); m != nil && strings.Trim(m[2], `"' `) != "" {
+			findings = append(findings, Finding{File: file, Rule: "dotenv", Match: mask(m[2])})
+			return findings
+		}
+	}
+
+	for _, rule := range s.rules {
+		for _, match := range rule.pattern.FindAllString(content, -1) {
+			findings = append(findings, Finding{File: file, Rule: rule.name, Match: mask(match)})
+		}
+	}
+
+	// Entropy is only a fallback for lines no rule matched
+	if len(findings) == 0 {
+		for _, token := range s.highEntropyTokens(content) {
+			findings = append(findings, Finding{File: file, Rule: "high-entropy", Match: mask(token)})
+		}
+	}
+
+	return findings
+}
+
+// walk calls fn for every content line of a diff with the file it belongs
+// to, and rebuilds the diff from the returned lines. Headers are skipped;
+// they only occur between "diff --git" and the first hunk, so removed lines
+// such as "-- password='...'" inside hunks are still scanned.
+func (s *Scanner) walk(diff string, fn func(file string, lineNo int, content string) string) string {
+	lines := strings.Split(diff, "\n")
+	file := ""
+	header := true
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file = ""
+			header = true
+			if idx := strings.LastIndex(line, " b/"); idx >= 0 {
+				file = line[idx+3:]
+			}
+		case strings.HasPrefix(line, "@@"):
+			header = false
+		case header:
+			continue
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"), strings.HasPrefix(line, " "):
+			lines[i] = line[:1] + fn(file, i+1, line[1:])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (s *Scanner) highEntropyTokens(content string) []string {
+	if s.entropyThreshold <= 0 || s.entropyMinLength <= 0 {
+		return nil
+	}
+
+	var tokens []string
+	for _, token := range tokenPattern.FindAllString(content, -1) {
+		if len(token) >= s.entropyMinLength && hasMixedCharacters(token) && entropy(token) >= s.entropyThreshold {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// entropy returns the Shannon entropy of s in bits per character
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+
+	var bits float64
+	n := float64(len(s))
+	for _, c := range counts {
+		p := float64(c) / n
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
+
+// hasMixedCharacters filters out long identifiers and paths, which rarely
+// contain digits alongside both letter cases
+func hasMixedCharacters(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+func isDotenv(file string) bool {
+	base := path.Base(file)
+	return base == ".env" || (strings.HasPrefix(base, ".env.") && base != ".env.example" && base != ".env.sample")
+}
+
+func mask(s string) string {
+	if len(s) <= 8 {
+		return "********"
+	}
+	return s[:4] + "..." + s[len(s)-2:]
+}
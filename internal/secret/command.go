@@ -0,0 +1,29 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RunKeyCommand runs a shell command such as "pass show openai" and returns
+// the first line of its output as the API key
+func RunKeyCommand(command string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("api_key_command failed: %w\n%s", err, stderr.String())
+	}
+
+	key, _, _ := strings.Cut(string(output), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("api_key_command %q printed no key", command)
+	}
+	return key, nil
+}
@@ -0,0 +1,211 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// PassphraseEnv can hold the passphrase for the encrypted file backend
+const PassphraseEnv = "ANC_PASSPHRASE"
+
+const (
+	fileName         = "secrets.enc"
+	pbkdf2Iterations = 600000
+	keyLength        = 32 // AES-256
+)
+
+// FileStore keeps secrets in a file encrypted with AES-GCM under a key
+// derived from a passphrase
+type FileStore struct {
+	path       string
+	passphrase func() (string, error)
+	cached     string
+}
+
+// encryptedFile is the on-disk format of the store
+type encryptedFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+// NewFileStore creates a store in dir that asks passphrase for the
+// passphrase the first time it is needed
+func NewFileStore(dir string, passphrase func() (string, error)) *FileStore {
+	return &FileStore{
+		path:       filepath.Join(dir, fileName),
+		passphrase: passphrase,
+	}
+}
+
+// PromptPassphrase reads the passphrase from ANC_PASSPHRASE or, failing
+// that, from the terminal without echoing it
+func PromptPassphrase() (string, error) {
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("a passphrase is required: set %s or run anc in a terminal", PassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase for encrypted API keys: ")
+	passphrase, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) == 0 {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	return string(passphrase), nil
+}
+
+// Unlock asks for the passphrase now and checks it against the existing
+// file, if any
+func (f *FileStore) Unlock() error {
+	if f.cached == "" {
+		passphrase, err := f.passphrase()
+		if err != nil {
+			return err
+		}
+		f.cached = passphrase
+	}
+	_, err := f.load()
+	return err
+}
+
+func (f *FileStore) Name() string {
+	return "encrypted file " + f.path
+}
+
+func (f *FileStore) Get(account string) (string, error) {
+	secrets, err := f.load()
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := secrets[account]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (f *FileStore) Set(account, secret string) error {
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[account] = secret
+	return f.save(secrets)
+}
+
+func (f *FileStore) Delete(account string) error {
+	secrets, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	delete(secrets, account)
+	return f.save(secrets)
+}
+
+func (f *FileStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("corrupt secrets file %s: %w", f.path, err)
+	}
+
+	gcm, err := f.cipher(file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		f.cached = ""
+		return nil, fmt.Errorf("failed to decrypt %s: wrong passphrase?", f.path)
+	}
+
+	secrets := map[string]string{}
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("corrupt secrets file %s: %w", f.path, err)
+	}
+	return secrets, nil
+}
+
+func (f *FileStore) save(secrets map[string]string) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{
+		Salt: make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+
+	gcm, err := f.cipher(file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(f.path, data, 0600)
+}
+
+func (f *FileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	if f.cached == "" {
+		passphrase, err := f.passphrase()
+		if err != nil {
+			return nil, err
+		}
+		f.cached = passphrase
+	}
+
+	key, err := pbkdf2.Key(sha256.New, f.cached, salt, pbkdf2Iterations, keyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
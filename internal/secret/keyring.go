@@ -0,0 +1,67 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// service identifies anc's entries in the keyring
+const service = "anc"
+
+// Keyring stores secrets in the Secret Service (GNOME Keyring, KWallet)
+// through libsecret's secret-tool
+type Keyring struct{}
+
+// NewKeyring creates a keyring store
+func NewKeyring() *Keyring {
+	return &Keyring{}
+}
+
+func (k *Keyring) Name() string {
+	return "system keyring (Secret Service)"
+}
+
+func (k *Keyring) Get(account string) (string, error) {
+	output, err := k.run("", "lookup", "service", service, "account", account)
+	if err != nil {
+		// secret-tool exits with status 1 and no output for missing entries
+		if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 && output == "" {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return strings.TrimRight(output, "\n"), nil
+}
+
+func (k *Keyring) Set(account, secret string) error {
+	_, err := k.run(secret, "store", "--label", "anc API key ("+account+")", "service", service, "account", account)
+	return err
+}
+
+func (k *Keyring) Delete(account string) error {
+	_, err := k.run("", "clear", "service", service, "account", account)
+	return err
+}
+
+func (k *Keyring) run(stdin string, args ...string) (string, error) {
+	if _, err := exec.LookPath("secret-tool"); err != nil {
+		return "", fmt.Errorf("keyring backend requires secret-tool (install libsecret-tools): %w", err)
+	}
+
+	cmd := exec.Command("secret-tool", args...)
+	cmd.Stdin = strings.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if stderr.Len() > 0 {
+			return stdout.String(), fmt.Errorf("secret-tool %s failed: %w\n%s", args[0], err, stderr.String())
+		}
+		return stdout.String(), err
+	}
+	return stdout.String(), nil
+}
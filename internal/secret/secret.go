@@ -0,0 +1,46 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Backend names as used in the configuration
+const (
+	BackendConfig  = "config"  // Plaintext in config.json
+	BackendKeyring = "keyring" // Secret Service via libsecret
+	BackendFile    = "file"    // Passphrase-encrypted file
+)
+
+// ErrNotFound is returned when a store has no secret for an account
+var ErrNotFound = errors.New("secret not found")
+
+// Store keeps API keys outside of the plaintext configuration
+type Store interface {
+	// Name describes where the secrets are kept
+	Name() string
+	Get(account string) (string, error)
+	Set(account, secret string) error
+	Delete(account string) error
+}
+
+// Unlocker is implemented by stores that need user input, such as a
+// passphrase, before they can be used. Unlock asks for it right away so
+// it isn't requested later while a TUI owns the terminal.
+type Unlocker interface {
+	Unlock() error
+}
+
+// Open returns the store for a backend name. The config backend has no
+// store and returns nil.
+func Open(backend, configDir string) (Store, error) {
+	switch backend {
+	case "", BackendConfig:
+		return nil, nil
+	case BackendKeyring:
+		return NewKeyring(), nil
+	case BackendFile:
+		return NewFileStore(configDir, PromptPassphrase), nil
+	}
+	return nil, fmt.Errorf("unknown key backend %q: must be '%s', '%s' or '%s'", backend, BackendConfig, BackendKeyring, BackendFile)
+}
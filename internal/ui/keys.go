@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds the key bindings of every screen. Actions on different
+// screens may share a key, but each can be rebound on its own.
+type KeyMap struct {
+	// Shared by all screens
+	Up    key.Binding
+	Down  key.Binding
+	Left  key.Binding
+	Right key.Binding
+	Enter key.Binding
+	Back  key.Binding
+	Quit  key.Binding
+	Help  key.Binding
+
+	// File selection
+	Toggle    key.Binding
+	ToggleAll key.Binding
+	TreeView  key.Binding
+
+	// Configuration editor, where letters are typed into the fields
+	NextField   key.Binding
+	PrevField   key.Binding
+	NextProfile key.Binding
+
+	// Mode selection
+	Profile key.Binding
+	Drafts  key.Binding
+
+	// Already staged files
+	Continue    key.Binding
+	Unstage     key.Binding
+	ResumeDraft key.Binding
+
+	// Message review
+	Edit        key.Binding
+	OpenEditor  key.Binding
+	Regenerate  key.Binding
+	Fix         key.Binding
+	Trailers    key.Binding
+	ToggleHooks key.Binding
+	PrevVersion key.Binding
+	NextVersion key.Binding
+	Diff        key.Binding
+
+	// Message editing, where plain keys type text
+	Save           key.Binding
+	ExternalEditor key.Binding
+
+	// Secrets warning
+	Redact key.Binding
+
+	// Split editor
+	NewGroup key.Binding
+
+	// Failed commits
+	Retry   key.Binding
+	Restage key.Binding
+
+	// After committing
+	Push        key.Binding
+	SetUpstream key.Binding
+	CopySHA     key.Binding
+
+	// Trailers
+	SignOff       key.Binding
+	CoAuthor      key.Binding
+	AddTrailer    key.Binding
+	DeleteTrailer key.Binding
+}
+
+func binding(help, desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(help, desc))
+}
+
+// DefaultKeyMap returns the default key bindings
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up:    binding("↑/k", "up", "up", "k"),
+		Down:  binding("↓/j", "down", "down", "j"),
+		Left:  binding("←/h", "left", "left", "h"),
+		Right: binding("→/l", "right", "right", "l"),
+		Enter: binding("enter", "select", "enter"),
+		Back:  binding("esc", "back", "esc"),
+		Quit:  binding("q", "quit", "q"),
+		Help:  binding("?", "help", "?"),
+
+		Toggle:    binding("space", "toggle", " "),
+		ToggleAll: binding("a", "all/none", "a"),
+		TreeView:  binding("t", "tree/flat view", "t"),
+
+		NextField:   binding("tab/↓", "next field", "tab", "down"),
+		PrevField:   binding("shift+tab/↑", "previous field", "shift+tab", "up"),
+		NextProfile: binding("ctrl+p", "switch profile", "ctrl+p"),
+
+		Profile: binding("p", "switch profile", "p"),
+		Drafts:  binding("r", "recent drafts", "r"),
+
+		Continue:    binding("c", "continue with staged files", "c"),
+		Unstage:     binding("u", "unstage", "u"),
+		ResumeDraft: binding("d", "resume draft", "d"),
+
+		Edit:        binding("e", "edit", "e"),
+		OpenEditor:  binding("o", "open in $EDITOR", "o"),
+		Regenerate:  binding("r", "regenerate", "r"),
+		Fix:         binding("f", "auto-fix", "f"),
+		Trailers:    binding("t", "trailers", "t"),
+		ToggleHooks: binding("v", "toggle hooks", "v"),
+		PrevVersion: binding("[", "previous version", "["),
+		NextVersion: binding("]", "next version", "]"),
+		Diff:        binding("d", "diff", "d"),
+
+		Save:           binding("ctrl+s", "save", "ctrl+s", "ctrl+d"),
+		ExternalEditor: binding("ctrl+o", "open in $EDITOR", "ctrl+o"),
+
+		Redact: binding("r", "redact and send", "r"),
+
+		NewGroup: binding("n", "new commit", "n"),
+
+		Retry:   binding("r", "retry", "r"),
+		Restage: binding("s", "re-stage modified files and retry", "s"),
+
+		Push:        binding("p", "push", "p"),
+		SetUpstream: binding("u", "push and set upstream", "u"),
+		CopySHA:     binding("c", "copy SHA", "c"),
+
+		SignOff:       binding("s", "toggle sign-off", "s"),
+		CoAuthor:      binding("c", "add co-author", "c"),
+		AddTrailer:    binding("a", "add custom", "a"),
+		DeleteTrailer: binding("d", "delete", "d", "backspace"),
+	}
+}
+
+// actions names the bindings for configuration overrides
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"up":              &k.Up,
+		"down":            &k.Down,
+		"left":            &k.Left,
+		"right":           &k.Right,
+		"enter":           &k.Enter,
+		"back":            &k.Back,
+		"quit":            &k.Quit,
+		"help":            &k.Help,
+		"toggle":          &k.Toggle,
+		"toggle_all":      &k.ToggleAll,
+		"tree_view":       &k.TreeView,
+		"next_field":      &k.NextField,
+		"prev_field":      &k.PrevField,
+		"next_profile":    &k.NextProfile,
+		"profile":         &k.Profile,
+		"drafts":          &k.Drafts,
+		"continue":        &k.Continue,
+		"unstage":         &k.Unstage,
+		"resume_draft":    &k.ResumeDraft,
+		"edit":            &k.Edit,
+		"open_editor":     &k.OpenEditor,
+		"regenerate":      &k.Regenerate,
+		"fix":             &k.Fix,
+		"trailers":        &k.Trailers,
+		"toggle_hooks":    &k.ToggleHooks,
+		"prev_version":    &k.PrevVersion,
+		"next_version":    &k.NextVersion,
+		"diff":            &k.Diff,
+		"save":            &k.Save,
+		"external_editor": &k.ExternalEditor,
+		"redact":          &k.Redact,
+		"new_group":       &k.NewGroup,
+		"retry":           &k.Retry,
+		"restage":         &k.Restage,
+		"push":            &k.Push,
+		"set_upstream":    &k.SetUpstream,
+		"copy_sha":        &k.CopySHA,
+		"sign_off":        &k.SignOff,
+		"co_author":       &k.CoAuthor,
+		"add_trailer":     &k.AddTrailer,
+		"delete_trailer":  &k.DeleteTrailer,
+	}
+}
+
+// NewKeyMap returns the default key bindings with overrides applied. Each
+// override replaces all keys of an action, named like "regenerate" or
+// "toggle_all". Unknown actions are reported, the other overrides still
+// apply.
+func NewKeyMap(overrides map[string][]string) (KeyMap, error) {
+	keys := DefaultKeyMap()
+	actions := keys.actions()
+
+	var unknown []string
+	for name, keyNames := range overrides {
+		b, ok := actions[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if len(keyNames) == 0 {
+			b.SetEnabled(false)
+			continue
+		}
+		b.SetKeys(keyNames...)
+		b.SetHelp(helpKeys(keyNames), b.Help().Desc)
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return keys, fmt.Errorf("unknown key binding action(s): %s", strings.Join(unknown, ", "))
+	}
+	return keys, nil
+}
+
+// helpKeys shows keys the way the default help does
+func helpKeys(keys []string) string {
+	symbols := map[string]string{
+		" ":     "space",
+		"up":    "↑",
+		"down":  "↓",
+		"left":  "←",
+		"right": "→",
+	}
+	shown := make([]string, len(keys))
+	for i, k := range keys {
+		if s, ok := symbols[k]; ok {
+			k = s
+		}
+		shown[i] = k
+	}
+	return strings.Join(shown, "/")
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// SplitWidth is the terminal width from which screens show a side pane
+	SplitWidth = 120
+
+	// MaxMainWidth keeps single pane screens readable on wide terminals
+	MaxMainWidth = 100
+
+	paneGap = 2
+)
+
+// Pane is the space available to a part of the screen
+type Pane struct {
+	Width  int
+	Height int
+}
+
+// Layout divides the terminal into a main pane and, on wide terminals, a
+// side pane next to it. It is recomputed on every resize.
+type Layout struct {
+	Width  int
+	Height int
+	Main   Pane
+	Side   Pane // Zero when the terminal is too narrow to split
+}
+
+// NewLayout lays out a terminal of the given size, leaving a margin around
+// the content
+func NewLayout(width, height int) Layout {
+	l := Layout{Width: width, Height: height}
+
+	usable := max(width-4, 20)
+	l.Main = Pane{Width: usable, Height: max(height-2, 10)}
+
+	if width >= SplitWidth {
+		l.Side.Width = usable * 2 / 5
+		l.Side.Height = l.Main.Height
+		l.Main.Width = usable - l.Side.Width - paneGap
+	} else if l.Main.Width > MaxMainWidth {
+		l.Main.Width = MaxMainWidth
+	}
+	return l
+}
+
+// Split reports whether there is room for a side pane
+func (l Layout) Split() bool {
+	return l.Side.Width > 0
+}
+
+// Join places the side pane next to the main pane, in a border of height
+// lines. Without a side pane, main is returned as is.
+func (l Layout) Join(main, side string, height int) string {
+	if !l.Split() {
+		return main
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Padding(0, 1).
+		Width(l.Side.Width - 2).
+		Height(max(height-2, 1)).
+		MaxHeight(height).
+		Render(side)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(l.Main.Width).Render(main),
+		strings.Repeat(" ", paneGap),
+		box,
+	)
+}
+
+// Clip cuts text to at most width columns and height lines without
+// wrapping, keeping ANSI styling intact
+func Clip(text string, width, height int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	style := lipgloss.NewStyle().MaxWidth(width)
+	for i, line := range lines {
+		lines[i] = style.Render(line)
+	}
+	return strings.Join(lines, "\n")
+}
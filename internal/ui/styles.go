@@ -3,9 +3,9 @@ package ui
 import (
 	"fmt"
 	"io"
+	"path"
 	"strings"
 
-	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -36,63 +36,14 @@ var (
 		Foreground(lipgloss.AdaptiveColor{Light: "#FF4672", Dark: "#ED567A"}).
 		Bold(true)
 
+	WarningStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.AdaptiveColor{Light: "#FF9500", Dark: "#FFCC00"})
+
 	SuccessStyle = lipgloss.NewStyle().
 		Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"}).
 		Bold(true)
 )
 
-// Key bindings
-type KeyMap struct {
-	Up       key.Binding
-	Down     key.Binding
-	Left     key.Binding
-	Right    key.Binding
-	Enter    key.Binding
-	Space    key.Binding
-	Quit     key.Binding
-	Help     key.Binding
-	ToggleAll key.Binding
-}
-
-var Keys = KeyMap{
-	Up: key.NewBinding(
-		key.WithKeys("up", "k"),
-		key.WithHelp("↑/k", "up"),
-	),
-	Down: key.NewBinding(
-		key.WithKeys("down", "j"),
-		key.WithHelp("↓/j", "down"),
-	),
-	Left: key.NewBinding(
-		key.WithKeys("left", "h"),
-		key.WithHelp("←/h", "left"),
-	),
-	Right: key.NewBinding(
-		key.WithKeys("right", "l"),
-		key.WithHelp("→/l", "right"),
-	),
-	Enter: key.NewBinding(
-		key.WithKeys("enter"),
-		key.WithHelp("enter", "select"),
-	),
-	Space: key.NewBinding(
-		key.WithKeys(" "),
-		key.WithHelp("space", "toggle"),
-	),
-	Quit: key.NewBinding(
-		key.WithKeys("q", "ctrl+c"),
-		key.WithHelp("q", "quit"),
-	),
-	Help: key.NewBinding(
-		key.WithKeys("?"),
-		key.WithHelp("?", "help"),
-	),
-	ToggleAll: key.NewBinding(
-		key.WithKeys("a"),
-		key.WithHelp("a", "toggle all"),
-	),
-}
-
 // Helper functions
 func Title(s string) string {
 	return TitleStyle.Render(s)
@@ -102,12 +53,14 @@ func Subtle(s string) string {
 	return SubtleStyle.Render(s)
 }
 
+// CenterInWindow centers a block of text in the window. Widths are
+// measured in terminal cells, ignoring ANSI styling.
 func CenterInWindow(s string, width, height int) string {
 	lines := strings.Split(s, "\n")
 	maxLineWidth := 0
 	for _, line := range lines {
-		if len(line) > maxLineWidth {
-			maxLineWidth = len(line)
+		if w := lipgloss.Width(line); w > maxLineWidth {
+			maxLineWidth = w
 		}
 	}
 
@@ -142,6 +95,8 @@ func CenterInWindow(s string, width, height int) string {
 type FileItem struct {
 	File     git.File
 	Selected bool
+	Depth    int  // Nesting in the tree view
+	Tree     bool // Show the name only, the directory rows above give the path
 }
 
 func (i FileItem) FilterValue() string { return i.File.Path }
@@ -158,6 +113,10 @@ func (d fileDelegate) Spacing() int                            { return 0 }
 func (d fileDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d fileDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	if dir, ok := listItem.(DirItem); ok {
+		d.renderDir(w, m, index, dir)
+		return
+	}
 	i, ok := listItem.(FileItem)
 	if !ok {
 		return
@@ -167,6 +126,7 @@ func (d fileDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	if i.Selected {
 		checkbox = "[✓]"
 	}
+	checkbox = strings.Repeat("  ", i.Depth) + checkbox
 	
 	// Add indicator if file is already staged
 	stagedIndicator := ""
@@ -174,32 +134,80 @@ func (d fileDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		stagedIndicator = " (staged)"
 	}
 	
-	statusColor := StatusStyle
-	switch i.File.Status {
-	case "M":
-		statusColor = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#FF9500", Dark: "#FFCC00"})
-	case "A":
-		statusColor = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"})
-	case "D":
-		statusColor = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#FF4672", Dark: "#ED567A"})
-	case "??":
-		statusColor = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9B9B9B", Dark: "#5C5C5C"})
+	status := statusStyle(i.File.Status).Render(i.File.Status)
+	name := i.File.Path
+	if i.Tree {
+		name = path.Base(name)
 	}
-	
-	status := statusColor.Render(i.File.Status)
-	path := i.File.Path + stagedIndicator
+	label := name + stagedIndicator
 
 	if index == m.Index() {
 		// Selected item
 		checkbox = SelectedStyle.Render(checkbox)
-		path = SelectedStyle.Render(path)
-		fmt.Fprintf(w, "%s %s %s %s", SelectedStyle.Render(">"), checkbox, status, path)
+		label = SelectedStyle.Render(label)
+		fmt.Fprintf(w, "%s %s %s %s", SelectedStyle.Render(">"), checkbox, status, label)
 	} else {
 		// Normal item
-		fmt.Fprintf(w, "  %s %s %s", NormalStyle.Render(checkbox), status, NormalStyle.Render(path))
+		fmt.Fprintf(w, "  %s %s %s", NormalStyle.Render(checkbox), status, NormalStyle.Render(label))
+	}
+}
+
+// renderDir renders a directory row with the selection of its files and
+// their counts by status
+func (d fileDelegate) renderDir(w io.Writer, m list.Model, index int, i DirItem) {
+	checkbox := "[ ]"
+	switch {
+	case i.Selected == i.Total:
+		checkbox = "[✓]"
+	case i.Selected > 0:
+		checkbox = "[-]"
+	}
+	checkbox = strings.Repeat("  ", i.Depth) + checkbox
+
+	arrow := "▾"
+	if i.Collapsed {
+		arrow = "▸"
+	}
+
+	var counts []string
+	for _, c := range []struct {
+		status string
+		text   string
+		count  int
+	}{
+		{"M", "~", i.Counts.Modified},
+		{"A", "+", i.Counts.Added},
+		{"D", "-", i.Counts.Deleted},
+		{"??", "?", i.Counts.Untracked},
+	} {
+		if c.count > 0 {
+			counts = append(counts, statusStyle(c.status).Render(fmt.Sprintf("%s%d", c.text, c.count)))
+		}
+	}
+
+	name := arrow + " " + i.Name + "/"
+	if index == m.Index() {
+		fmt.Fprintf(w, "%s %s %s  %s", SelectedStyle.Render(">"), SelectedStyle.Render(checkbox), SelectedStyle.Render(name), strings.Join(counts, " "))
+	} else {
+		fmt.Fprintf(w, "  %s %s  %s", NormalStyle.Render(checkbox), NormalStyle.Render(name), strings.Join(counts, " "))
 	}
 }
 
+// statusStyle colors a file status
+func statusStyle(status string) lipgloss.Style {
+	switch status {
+	case "M":
+		return lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#FF9500", Dark: "#FFCC00"})
+	case "A":
+		return lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#04B575", Dark: "#04B575"})
+	case "D":
+		return lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#FF4672", Dark: "#ED567A"})
+	case "??":
+		return lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "#9B9B9B", Dark: "#5C5C5C"})
+	}
+	return StatusStyle
+}
+
 // ModeItem represents a commit mode in the list
 type ModeItem struct {
 	Name string
@@ -232,4 +240,37 @@ func (d modeDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		// Normal item
 		fmt.Fprintf(w, "  %s", NormalStyle.Render(i.Name))
 	}
-} 
\ No newline at end of file
+}
+
+// DraftItem represents a saved message draft in the list
+type DraftItem struct {
+	Subject string
+	Detail  string // When and where it was saved
+	Index   int
+}
+
+func (i DraftItem) FilterValue() string { return i.Subject }
+
+// draftDelegate handles rendering of draft items
+type draftDelegate struct{}
+
+func NewDraftDelegate() list.ItemDelegate {
+	return &draftDelegate{}
+}
+
+func (d draftDelegate) Height() int                             { return 1 }
+func (d draftDelegate) Spacing() int                            { return 0 }
+func (d draftDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+func (d draftDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(DraftItem)
+	if !ok {
+		return
+	}
+
+	if index == m.Index() {
+		fmt.Fprintf(w, "%s %s  %s", SelectedStyle.Render(">"), SelectedStyle.Render(i.Subject), Subtle(i.Detail))
+	} else {
+		fmt.Fprintf(w, "  %s  %s", NormalStyle.Render(i.Subject), Subtle(i.Detail))
+	}
+}
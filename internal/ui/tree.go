@@ -0,0 +1,252 @@
+package ui
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+)
+
+// StatusCounts counts the files of a directory by status
+type StatusCounts struct {
+	Modified  int
+	Added     int
+	Deleted   int
+	Untracked int
+}
+
+func (c *StatusCounts) add(status string) {
+	switch status {
+	case "A":
+		c.Added++
+	case "D":
+		c.Deleted++
+	case "??":
+		c.Untracked++
+	default:
+		c.Modified++
+	}
+}
+
+// DirItem is a directory row of the file tree
+type DirItem struct {
+	Path      string
+	Name      string // Path relative to the parent row, may span several directories
+	Depth     int
+	Collapsed bool
+	Selected  int // Number of its files selected
+	Total     int
+	Counts    StatusCounts
+}
+
+func (i DirItem) FilterValue() string { return i.Path }
+
+type treeDir struct {
+	path   string
+	name   string
+	dirs   []*treeDir
+	files  []git.File
+	counts StatusCounts
+	total  int
+}
+
+// FileTree keeps the changed files and which are selected, shown either as
+// a flat list or grouped by directory with collapsible folders
+type FileTree struct {
+	files     []git.File
+	root      *treeDir
+	flat      bool
+	collapsed map[string]bool
+	selected  map[string]bool
+}
+
+// NewFileTree builds the tree of files, with every folder expanded
+func NewFileTree(files []git.File, flat bool) *FileTree {
+	t := &FileTree{
+		files:     files,
+		root:      &treeDir{},
+		flat:      flat,
+		collapsed: map[string]bool{},
+		selected:  map[string]bool{},
+	}
+
+	dirs := map[string]*treeDir{"": t.root}
+	var dirFor func(dir string) *treeDir
+	dirFor = func(dir string) *treeDir {
+		if d, ok := dirs[dir]; ok {
+			return d
+		}
+		parent := dirFor(parentDir(dir))
+		d := &treeDir{path: dir, name: path.Base(dir)}
+		parent.dirs = append(parent.dirs, d)
+		dirs[dir] = d
+		return d
+	}
+
+	for _, f := range files {
+		d := dirFor(parentDir(f.Path))
+		d.files = append(d.files, f)
+		for dir := d.path; ; dir = parentDir(dir) {
+			dirs[dir].counts.add(f.Status)
+			dirs[dir].total++
+			if dir == "" {
+				break
+			}
+		}
+	}
+
+	t.root.sort()
+	t.root.compact()
+	return t
+}
+
+// parentDir returns the directory of a path, "" for the top level
+func parentDir(p string) string {
+	// Renames are shown as "old -> new", group them by the new path
+	if i := strings.Index(p, " -> "); i >= 0 {
+		p = p[i+4:]
+	}
+	dir := path.Dir(strings.TrimSuffix(p, "/"))
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+func (d *treeDir) sort() {
+	sort.Slice(d.dirs, func(i, j int) bool { return d.dirs[i].name < d.dirs[j].name })
+	sort.Slice(d.files, func(i, j int) bool { return d.files[i].Path < d.files[j].Path })
+	for _, sub := range d.dirs {
+		sub.sort()
+	}
+}
+
+// compact merges directories that only contain another directory, so deep
+// monorepo paths take one row
+func (d *treeDir) compact() {
+	for _, sub := range d.dirs {
+		for len(sub.files) == 0 && len(sub.dirs) == 1 {
+			child := sub.dirs[0]
+			child.name = sub.name + "/" + child.name
+			*sub = *child
+		}
+		sub.compact()
+	}
+}
+
+// SetFlat switches between the flat list and the tree
+func (t *FileTree) SetFlat(flat bool) {
+	t.flat = flat
+}
+
+// Flat reports whether the files are shown as a flat list
+func (t *FileTree) Flat() bool {
+	return t.flat
+}
+
+// Items returns the visible rows for a list
+func (t *FileTree) Items() []list.Item {
+	var items []list.Item
+	if t.flat {
+		for _, f := range t.files {
+			items = append(items, FileItem{File: f, Selected: t.selected[f.Path]})
+		}
+		return items
+	}
+
+	var walk func(d *treeDir, depth int)
+	walk = func(d *treeDir, depth int) {
+		for _, sub := range d.dirs {
+			items = append(items, DirItem{
+				Path:      sub.path,
+				Name:      sub.name,
+				Depth:     depth,
+				Collapsed: t.collapsed[sub.path],
+				Selected:  len(t.selectedIn(sub)),
+				Total:     sub.total,
+				Counts:    sub.counts,
+			})
+			if !t.collapsed[sub.path] {
+				walk(sub, depth+1)
+			}
+		}
+		for _, f := range d.files {
+			items = append(items, FileItem{File: f, Selected: t.selected[f.Path], Depth: depth, Tree: true})
+		}
+	}
+	walk(t.root, 0)
+	return items
+}
+
+// Toggle flips the selection of a file, or of all files in a directory:
+// they are all selected unless they already were
+func (t *FileTree) Toggle(item list.Item) {
+	switch i := item.(type) {
+	case FileItem:
+		t.selected[i.File.Path] = !t.selected[i.File.Path]
+	case DirItem:
+		d := t.find(t.root, i.Path)
+		if d == nil {
+			return
+		}
+		all := len(t.selectedIn(d)) == d.total
+		t.setSelected(d, !all)
+	}
+}
+
+// ToggleAll selects every file, or none if all are selected
+func (t *FileTree) ToggleAll() {
+	t.setSelected(t.root, len(t.Selected()) != len(t.files))
+}
+
+// SetCollapsed collapses or expands a directory
+func (t *FileTree) SetCollapsed(dir string, collapsed bool) {
+	t.collapsed[dir] = collapsed
+}
+
+// Selected returns the paths of the selected files in list order
+func (t *FileTree) Selected() []string {
+	var paths []string
+	for _, f := range t.files {
+		if t.selected[f.Path] {
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
+func (t *FileTree) find(d *treeDir, dir string) *treeDir {
+	if d.path == dir {
+		return d
+	}
+	for _, sub := range d.dirs {
+		if found := t.find(sub, dir); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func (t *FileTree) selectedIn(d *treeDir) []string {
+	var paths []string
+	for _, f := range d.files {
+		if t.selected[f.Path] {
+			paths = append(paths, f.Path)
+		}
+	}
+	for _, sub := range d.dirs {
+		paths = append(paths, t.selectedIn(sub)...)
+	}
+	return paths
+}
+
+func (t *FileTree) setSelected(d *treeDir, selected bool) {
+	for _, f := range d.files {
+		t.selected[f.Path] = selected
+	}
+	for _, sub := range d.dirs {
+		t.setSelected(sub, selected)
+	}
+}
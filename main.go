@@ -10,6 +10,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/oconnorjohnson/add-n-commit/internal/app"
 	"github.com/oconnorjohnson/add-n-commit/internal/config"
+	"github.com/oconnorjohnson/add-n-commit/internal/git"
+	"github.com/oconnorjohnson/add-n-commit/internal/ui"
 )
 
 // Build variables set by goreleaser
@@ -22,13 +24,22 @@ var (
 func main() {
 	// Define command-line flags
 	var (
-		setKey    = flag.String("set-key", "", "Set the OpenAI API key")
-		showKey   = flag.Bool("show-key", false, "Show the current OpenAI API key")
-		deleteKey = flag.Bool("delete-key", false, "Delete the stored OpenAI API key")
-		configure = flag.Bool("config", false, "Open configuration editor")
-		showHelp  = flag.Bool("help", false, "Show help")
+		setKey      = flag.String("set-key", "", "Set the OpenAI API key")
+		showKey     = flag.Bool("show-key", false, "Show the current OpenAI API key")
+		deleteKey   = flag.Bool("delete-key", false, "Delete the stored OpenAI API key")
+		migrateKey  = flag.String("migrate-key", "", "Move stored API keys to a backend (config, keyring, file)")
+		configure   = flag.Bool("config", false, "Open configuration editor")
+		profile     = flag.String("profile", "", "Use the named configuration profile")
+		amend       = flag.Bool("amend", false, "Update the last commit and its message")
+		session     = flag.Bool("session", false, "Keep committing until the working tree is clean")
+		noVerify    = flag.Bool("no-verify", false, "Skip the pre-commit and commit-msg hooks")
+		sign        = flag.Bool("sign", false, "Sign the commits")
+		noSign      = flag.Bool("no-sign", false, "Don't sign the commits, even if commit.gpgSign is set")
+		signingKey  = flag.String("signing-key", "", "Sign the commits with this key")
+		showHelp    = flag.Bool("help", false, "Show help")
 		versionFlag = flag.Bool("version", false, "Show version")
 	)
+	flag.BoolVar(sign, "S", false, "Sign the commits (shorthand for --sign)")
 
 	flag.Parse()
 
@@ -63,7 +74,21 @@ func main() {
 	}
 
 	if *showKey {
-		handleShowKey(cfg)
+		// Report the key this run would use, not only the user config's
+		layered, err := layerConfig(cfg, *profile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := handleShowKey(layered); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *migrateKey != "" {
+		if err := handleMigrateKey(cfg, *migrateKey); err != nil {
+			log.Fatal(err)
+		}
 		return
 	}
 
@@ -74,10 +99,23 @@ func main() {
 		return
 	}
 
+	// Report mistyped key binding actions before the TUI takes over
+	if _, err := ui.NewKeyMap(cfg.Keys); err != nil {
+		log.Fatal(err)
+	}
+
 	if *configure {
+		if err := cfg.UnlockSecretStore(); err != nil {
+			log.Fatal(err)
+		}
+
 		// Run configuration editor
+		editor := config.NewConfigEditor(cfg)
+		if *profile != "" {
+			editor.SetProfile(*profile)
+		}
 		p := tea.NewProgram(
-			config.NewConfigEditor(cfg),
+			editor,
 			tea.WithAltScreen(),
 		)
 		if _, err := p.Run(); err != nil {
@@ -91,16 +129,75 @@ func main() {
 		log.Fatal("Error: Not in a git repository")
 	}
 
+	// Layer the project config and the selected profile over the user config
+	if cfg, err = layerConfig(cfg, *profile); err != nil {
+		log.Fatal(err)
+	}
+
+	// Resolve the API key from the environment, key command or secret store
+	apiKey, _, err := cfg.APIKey()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg.OpenAIKey = apiKey
+
+	// Without a key, the TUI asks for one and saves it to the store
+	if apiKey == "" && cfg.NeedsAPIKey() {
+		if err := cfg.UnlockSecretStore(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	opts := app.Options{
+		Amend:      *amend,
+		Session:    *session,
+		NoVerify:   *noVerify,
+		Sign:       *sign || *signingKey != "",
+		NoSign:     *noSign,
+		SigningKey: *signingKey,
+	}
+	if *amend && *session {
+		log.Fatal("--amend can't be combined with --session")
+	}
+	if opts.Sign && opts.NoSign {
+		log.Fatal("--sign and --signing-key can't be combined with --no-sign")
+	}
+
+	// Subcommands
+	if args := flag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "reword":
+			if len(args) != 2 {
+				log.Fatal("Usage: anc reword <rev>")
+			}
+			if *amend || *session {
+				log.Fatal("--amend and --session can't be combined with reword")
+			}
+			opts.Reword = args[1]
+		default:
+			log.Fatalf("Unknown command %q, see anc --help", args[0])
+		}
+	}
+
 	// Create and run the app
 	p := tea.NewProgram(
-		app.New(cfg),
+		app.NewWithOptions(cfg, opts),
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
 
-	if _, err := p.Run(); err != nil {
+	final, err := p.Run()
+	if err != nil {
 		log.Fatal(err)
 	}
+
+	// Leave the session's commits in the terminal scrollback
+	if m, ok := final.(*app.Model); ok && len(m.SessionLog()) > 0 {
+		fmt.Printf("Committed %d commit(s) this session:\n", len(m.SessionLog()))
+		for _, c := range m.SessionLog() {
+			fmt.Printf("  %s %s\n", c.Hash, c.Subject)
+		}
+	}
 }
 
 func checkGitRepo() error {
@@ -116,15 +213,28 @@ func printHelp() {
 
 USAGE:
     anc [options]
+    anc [options] reword <rev>
 
 OPTIONS:
     --set-key <key>    Set the OpenAI API key
     --show-key         Show the current OpenAI API key (masked)
     --delete-key       Delete the stored OpenAI API key
+    --migrate-key <b>  Move stored API keys to a backend: config, keyring, file
     --config           Open interactive configuration editor
+    --profile <name>   Use a named configuration profile
+    --amend            Add staged changes to the last commit and regenerate its message
+    --session          Return to file selection after each commit until you quit
+    --no-verify        Skip the pre-commit and commit-msg hooks (toggle with v in review)
+    -S, --sign         Sign the commits, whatever "sign" and commit.gpgSign say
+    --no-sign          Don't sign the commits
+    --signing-key <k>  Sign the commits with this key (implies --sign)
     --version          Show version information
     --help             Show this help message
 
+COMMANDS:
+    reword <rev>       Generate a new message for an earlier commit on the
+                       current branch and rewrite it with a rebase
+
 INTERACTIVE MODE:
     Run 'anc' without options to enter interactive mode where you can:
     - Select files to stage
@@ -133,7 +243,11 @@ INTERACTIVE MODE:
     - Commit your changes
 
 CONFIGURATION:
-    API keys are stored in ~/.config/anc/config.json
+    Settings are stored in ~/.config/anc/config.json
+    API keys are stored there too unless "key_backend" is "keyring" (Secret
+    Service via secret-tool) or "file" (encrypted with a passphrase, read from
+    ANC_PASSPHRASE or prompted). "api_key_command" runs a command such as
+    "pass show openai" to get the key.
     You can also set the OPENAI_API_KEY environment variable
 
 EXAMPLES:
@@ -141,7 +255,13 @@ EXAMPLES:
     anc --set-key sk-...        # Set your OpenAI API key
     anc --show-key              # View your current API key (masked)
     anc --delete-key            # Remove stored API key
-    anc --config                # Open configuration editor`)
+    anc --migrate-key keyring   # Move plaintext keys into the system keyring
+    anc --config                # Open configuration editor
+    anc --profile work          # Use the "work" profile
+    anc --amend                 # Reword or extend the last commit
+    anc --session               # Work through a dirty tree in several commits
+    anc reword HEAD~3           # Regenerate the message of an older commit
+    anc --config --profile work # Create or edit the "work" profile`)
 }
 
 func handleSetKey(cfg *config.Config, key string) error {
@@ -150,40 +270,88 @@ func handleSetKey(cfg *config.Config, key string) error {
 		return fmt.Errorf("invalid API key format: OpenAI API keys should start with 'sk-'")
 	}
 
-	cfg.OpenAIKey = key
-	if err := cfg.Save(); err != nil {
+	store, err := cfg.SecretStore()
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.SetAPIKey(key); err != nil {
 		return fmt.Errorf("failed to save configuration: %w", err)
 	}
 
 	fmt.Println("✓ OpenAI API key saved successfully")
-	fmt.Println("Configuration stored in ~/.config/anc/config.json")
+	if store != nil {
+		fmt.Printf("Key stored in %s\n", store.Name())
+	} else {
+		fmt.Println("Configuration stored in ~/.config/anc/config.json")
+		fmt.Println("Use 'anc --migrate-key keyring' to move it out of the plaintext config")
+	}
 	return nil
 }
 
-func handleShowKey(cfg *config.Config) {
-	if cfg.OpenAIKey == "" {
+// layerConfig layers the repository's project config, when run inside a
+// repository, and the selected profile over the user config. Without a
+// --profile flag the project's and then the user's "profile" setting is
+// used.
+func layerConfig(cfg *config.Config, profile string) (*config.Config, error) {
+	if root, err := git.GetRepoRoot(); err == nil {
+		if cfg, err = cfg.WithProject(root); err != nil {
+			return nil, err
+		}
+	}
+
+	name := profile
+	if name == "" {
+		name = cfg.ActiveProfile
+	}
+	layered, err := cfg.WithProfile(name)
+	if err != nil {
+		if profile == "" && cfg.ProjectFile() != "" && cfg.User().ActiveProfile != name {
+			return nil, fmt.Errorf("%w (selected by %s)", err, cfg.ProjectFile())
+		}
+		return nil, err
+	}
+	return layered, nil
+}
+
+func handleShowKey(cfg *config.Config) error {
+	key, source, err := cfg.APIKey()
+	if err != nil {
+		return err
+	}
+
+	if key == "" {
 		fmt.Println("No OpenAI API key configured")
 		fmt.Println("Use 'anc --set-key <key>' to set one")
-		return
+		return nil
 	}
 
 	// Mask the key for security
-	maskedKey := maskAPIKey(cfg.OpenAIKey)
+	maskedKey := maskAPIKey(key)
 	fmt.Printf("Current OpenAI API key: %s\n", maskedKey)
+	fmt.Printf("Source: %s\n", source)
+	return nil
 }
 
 func handleDeleteKey(cfg *config.Config) error {
-	if cfg.OpenAIKey == "" {
-		fmt.Println("No OpenAI API key to delete")
-		return nil
+	if err := cfg.DeleteAPIKey(); err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
 	}
 
-	cfg.OpenAIKey = ""
-	if err := cfg.Save(); err != nil {
-		return fmt.Errorf("failed to save configuration: %w", err)
+	fmt.Println("✓ OpenAI API key deleted successfully")
+	if os.Getenv("OPENAI_API_KEY") != "" {
+		fmt.Println("Note: OPENAI_API_KEY is still set in your environment")
 	}
+	return nil
+}
 
-	fmt.Println("✓ OpenAI API key deleted successfully")
+func handleMigrateKey(cfg *config.Config, backend string) error {
+	moved, err := cfg.MigrateAPIKeys(backend)
+	if err != nil {
+		return fmt.Errorf("failed to migrate API keys: %w", err)
+	}
+
+	fmt.Printf("✓ Moved %d API key(s) to the %s backend\n", moved, backend)
 	return nil
 }
 
@@ -193,4 +361,4 @@ func maskAPIKey(key string) string {
 	}
 	// Show first 3 and last 4 characters
 	return key[:3] + "..." + key[len(key)-4:]
-} 
\ No newline at end of file
+}